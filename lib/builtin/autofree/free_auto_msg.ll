@@ -5,7 +5,7 @@ source_filename = "lib/builtin/autofree/free_auto_msg.ll"
 @.str0 = private unnamed_addr constant [19 x i32] [i32 65, i32 117, i32 116, i32 111, i32 109, i32 97, i32 116, i32 105, i32 99, i32 97, i32 108, i32 108, i32 121, i32 32, i32 102, i32 114, i32 101, i32 101, i32 100], align 4
 
 declare fastcc %type.string @".copy:string"(%type.string)
-declare fastcc void @.println(%type.string)
+declare fastcc void @".println:string"(%type.string)
 
 define fastcc void @freeAutoMsg() {
 entry:
@@ -17,7 +17,7 @@ entry:
 	store i32* %0, i32** %3, align 8
 	%4 = load %type.string, %type.string* %1, align 8
 	%5 = call %type.string @".copy:string"(%type.string %4)
-	call void @.println(%type.string %5)
+	call void @".println:string"(%type.string %5)
 	br label %exit
 
 exit:
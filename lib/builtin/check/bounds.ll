@@ -0,0 +1,29 @@
+source_filename = "lib/builtin/check/bounds.ll"
+
+@.msg = private unnamed_addr constant [45 x i8] c"Index %d is out of range for a length of %d\0A\00", align 1
+
+declare i32 @dprintf(i32, i8*, ...)
+declare void @exit(i32) noreturn
+
+; Aborts the program when an index falls outside of an array or list
+define fastcc void @.outOfRange(i32 %idx, i32 %length) cold noreturn {
+entry:
+    %0 = getelementptr inbounds [45 x i8], [45 x i8]* @.msg, i32 0, i32 0
+    %1 = call i32 (i32, i8*, ...) @dprintf(i32 2, i8* %0, i32 %idx, i32 %length)
+    call void @exit(i32 1)
+    unreachable
+}
+
+; Negative indexes wrap around to large unsigned ones, so one comparison covers both ends
+define fastcc void @.bounds(i32 %idx, i32 %length) {
+entry:
+    %0 = icmp ult i32 %idx, %length
+    br i1 %0, label %ok, label %fail
+
+fail:
+    call fastcc void @.outOfRange(i32 %idx, i32 %length)
+    unreachable
+
+ok:
+    ret void
+}
@@ -0,0 +1,29 @@
+source_filename = "lib/builtin/check/element.ll"
+
+@.msg = private unnamed_addr constant [33 x i8] c"No element of %s has that value\0A\00", align 1
+
+declare i32 @dprintf(i32, i8*, ...)
+declare void @exit(i32) noreturn
+
+; Aborts the program when a value converted to an enum matches none of its elements
+define fastcc void @.noElement(i8* %enum) cold noreturn {
+entry:
+    %0 = getelementptr inbounds [33 x i8], [33 x i8]* @.msg, i32 0, i32 0
+    %1 = call i32 (i32, i8*, ...) @dprintf(i32 2, i8* %0, i8* %enum)
+    call void @exit(i32 1)
+    unreachable
+}
+
+; Elements are positions, where values that matched none of them are given as -1 and wrap around to a large unsigned one
+define fastcc i32 @.element(i32 %idx, i32 %length, i8* %enum) {
+entry:
+    %0 = icmp ult i32 %idx, %length
+    br i1 %0, label %ok, label %fail
+
+fail:
+    call fastcc void @.noElement(i8* %enum)
+    unreachable
+
+ok:
+    ret i32 %idx
+}
@@ -3,6 +3,7 @@ source_filename = "lib/builtin/function/print.ll"
 declare void @putchar(i32)
 
 %type.string = type { i32, i32* }
+%"type.string[]" = type { i32, %type.string* }
 
 define private fastcc void @printChar(i32 %cp) {
 entry:
@@ -85,7 +86,7 @@ if.end35:
     ret void
 }
 
-define fastcc void @.print(%type.string %str) {
+define fastcc void @".print:string"(%type.string %str) {
 entry:
     %ptr.str = alloca %type.string, align 8
 	store %type.string %str, %type.string* %ptr.str, align 8
@@ -119,9 +120,47 @@ for.exit:
     ret void
 }
 
-define fastcc void @.println(%type.string %str) {
+define fastcc void @".println:string"(%type.string %str) {
 entry:
-    call void @.print(%type.string %str)
+    call fastcc void @".print:string"(%type.string %str)
     call void @putchar(i32 10)
     ret void
-}
\ No newline at end of file
+}
+
+; Prints every string given to it, separated by spaces
+define fastcc void @.print(%"type.string[]" %strs) {
+entry:
+    %length = extractvalue %"type.string[]" %strs, 0
+    %addr = extractvalue %"type.string[]" %strs, 1
+    br label %for.cond
+
+for.cond:
+    %i = phi i32 [ 0, %entry ], [ %next, %for.print ]
+    %more = icmp slt i32 %i, %length
+    br i1 %more, label %for.body, label %for.exit
+
+for.body:
+    %gap = icmp sgt i32 %i, 0
+    br i1 %gap, label %for.space, label %for.print
+
+for.space:
+    call void @putchar(i32 32)
+    br label %for.print
+
+for.print:
+    %ptr = getelementptr inbounds %type.string, %type.string* %addr, i32 %i
+    %str = load %type.string, %type.string* %ptr, align 8
+    call fastcc void @".print:string"(%type.string %str)
+    %next = add i32 %i, 1
+    br label %for.cond
+
+for.exit:
+    ret void
+}
+
+define fastcc void @.println(%"type.string[]" %strs) {
+entry:
+    call fastcc void @.print(%"type.string[]" %strs)
+    call void @putchar(i32 10)
+    ret void
+}
@@ -0,0 +1,19 @@
+source_filename = "lib/builtin/hash/bool_hash.ll"
+
+define fastcc i32 @".hash:bool"(i8* %key) {
+entry:
+    %0 = bitcast i8* %key to i1*
+    %1 = load i1, i1* %0, align 1
+    %2 = zext i1 %1 to i32
+    ret i32 %2
+}
+
+define fastcc i1 @".equal:bool"(i8* %a, i8* %b) {
+entry:
+    %0 = bitcast i8* %a to i1*
+    %1 = load i1, i1* %0, align 1
+    %2 = bitcast i8* %b to i1*
+    %3 = load i1, i1* %2, align 1
+    %4 = icmp eq i1 %1, %3
+    ret i1 %4
+}
@@ -0,0 +1,27 @@
+source_filename = "lib/builtin/hash/float_hash.ll"
+
+declare fastcc i32 @".hash:int"(i8*)
+
+; Both zeros are equal, so -0 is hashed as 0
+define fastcc i32 @".hash:float"(i8* %key) {
+entry:
+    %0 = bitcast i8* %key to float*
+    %1 = load float, float* %0, align 4
+    %2 = fcmp oeq float %1, 0.0
+    %3 = select i1 %2, float 0.0, float %1
+    %bits = alloca float, align 4
+    store float %3, float* %bits, align 4
+    %4 = bitcast float* %bits to i8*
+    %5 = call fastcc i32 @".hash:int"(i8* %4)
+    ret i32 %5
+}
+
+define fastcc i1 @".equal:float"(i8* %a, i8* %b) {
+entry:
+    %0 = bitcast i8* %a to float*
+    %1 = load float, float* %0, align 4
+    %2 = bitcast i8* %b to float*
+    %3 = load float, float* %2, align 4
+    %4 = fcmp oeq float %1, %3
+    ret i1 %4
+}
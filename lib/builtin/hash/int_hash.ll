@@ -0,0 +1,27 @@
+source_filename = "lib/builtin/hash/int_hash.ll"
+
+; Mixes the bits of the integer with the finalizer of MurmurHash3, as the slots only use the low bits
+define fastcc i32 @".hash:int"(i8* %key) {
+entry:
+    %0 = bitcast i8* %key to i32*
+    %1 = load i32, i32* %0, align 4
+    %2 = lshr i32 %1, 16
+    %3 = xor i32 %1, %2
+    %4 = mul i32 %3, 2246822507
+    %5 = lshr i32 %4, 13
+    %6 = xor i32 %4, %5
+    %7 = mul i32 %6, 3266489909
+    %8 = lshr i32 %7, 16
+    %9 = xor i32 %7, %8
+    ret i32 %9
+}
+
+define fastcc i1 @".equal:int"(i8* %a, i8* %b) {
+entry:
+    %0 = bitcast i8* %a to i32*
+    %1 = load i32, i32* %0, align 4
+    %2 = bitcast i8* %b to i32*
+    %3 = load i32, i32* %2, align 4
+    %4 = icmp eq i32 %1, %3
+    ret i1 %4
+}
@@ -0,0 +1,60 @@
+source_filename = "lib/builtin/hash/string_hash.ll"
+
+%type.string = type { i32, i32* }
+
+declare i32 @memcmp(i8*, i8*, i64)
+
+; FNV-1a over the characters of the string
+define fastcc i32 @".hash:string"(i8* %key) {
+entry:
+    %0 = bitcast i8* %key to %type.string*
+    %1 = getelementptr inbounds %type.string, %type.string* %0, i32 0, i32 0
+    %length = load i32, i32* %1, align 4
+    %2 = getelementptr inbounds %type.string, %type.string* %0, i32 0, i32 1
+    %chars = load i32*, i32** %2, align 8
+    br label %cond
+
+cond:
+    %i = phi i32 [ 0, %entry ], [ %next, %loop ]
+    %hash = phi i32 [ 2166136261, %entry ], [ %5, %loop ]
+    %3 = icmp slt i32 %i, %length
+    br i1 %3, label %loop, label %end
+
+loop:
+    %4 = getelementptr inbounds i32, i32* %chars, i32 %i
+    %char = load i32, i32* %4, align 4
+    %mixed = xor i32 %hash, %char
+    %5 = mul i32 %mixed, 16777619
+    %next = add i32 %i, 1
+    br label %cond
+
+end:
+    ret i32 %hash
+}
+
+define fastcc i1 @".equal:string"(i8* %a, i8* %b) {
+entry:
+    %0 = bitcast i8* %a to %type.string*
+    %1 = load %type.string, %type.string* %0, align 8
+    %2 = bitcast i8* %b to %type.string*
+    %3 = load %type.string, %type.string* %2, align 8
+    %4 = extractvalue %type.string %1, 0
+    %5 = extractvalue %type.string %3, 0
+    %6 = icmp eq i32 %4, %5
+    br i1 %6, label %compare, label %end
+
+compare:
+    %7 = extractvalue %type.string %1, 1
+    %8 = bitcast i32* %7 to i8*
+    %9 = extractvalue %type.string %3, 1
+    %10 = bitcast i32* %9 to i8*
+    %11 = mul i32 %4, 4
+    %12 = zext i32 %11 to i64
+    %13 = call i32 @memcmp(i8* %8, i8* %10, i64 %12)
+    %14 = icmp eq i32 %13, 0
+    br label %end
+
+end:
+    %15 = phi i1 [ false, %entry ], [ %14, %compare ]
+    ret i1 %15
+}
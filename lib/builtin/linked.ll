@@ -6,9 +6,14 @@ source_filename = "llvm-link"
 %ref.float = type { float*, i32 }
 %union.anon = type { float }
 %ref.int = type { i32*, i32 }
+%type.list = type { i32, i32, i32, i8* }
+%type.map = type { i32, i32, i32, i32, i32, i8*, i8*, i8*, i32 (i8*)*, i1 (i8*, i8*)* }
+%"type.string[]" = type { i32, %type.string* }
 
 @.strTrue = private unnamed_addr constant [4 x i32] [i32 116, i32 114, i32 117, i32 101], align 4
 @.strFalse = private unnamed_addr constant [5 x i32] [i32 102, i32 97, i32 108, i32 115, i32 101], align 4
+@.msg = private unnamed_addr constant [45 x i8] c"Index %d is out of range for a length of %d\0A\00", align 1
+@.msg.1 = private unnamed_addr constant [33 x i8] c"No element of %s has that value\0A\00", align 1
 @FLOAT_POW5_INV_SPLIT = private unnamed_addr constant [31 x i64] [i64 576460752303423489, i64 461168601842738791, i64 368934881474191033, i64 295147905179352826, i64 472236648286964522, i64 377789318629571618, i64 302231454903657294, i64 483570327845851670, i64 386856262276681336, i64 309485009821345069, i64 495176015714152110, i64 396140812571321688, i64 316912650057057351, i64 507060240091291761, i64 405648192073033409, i64 324518553658426727, i64 519229685853482763, i64 415383748682786211, i64 332306998946228969, i64 531691198313966350, i64 425352958651173080, i64 340282366920938464, i64 544451787073501542, i64 435561429658801234, i64 348449143727040987, i64 557518629963265579, i64 446014903970612463, i64 356811923176489971, i64 570899077082383953, i64 456719261665907162, i64 365375409332725730], align 16
 @FLOAT_POW5_SPLIT = private unnamed_addr constant [47 x i64] [i64 1152921504606846976, i64 1441151880758558720, i64 1801439850948198400, i64 2251799813685248000, i64 1407374883553280000, i64 1759218604441600000, i64 2199023255552000000, i64 1374389534720000000, i64 1717986918400000000, i64 2147483648000000000, i64 1342177280000000000, i64 1677721600000000000, i64 2097152000000000000, i64 1310720000000000000, i64 1638400000000000000, i64 2048000000000000000, i64 1280000000000000000, i64 1600000000000000000, i64 2000000000000000000, i64 1250000000000000000, i64 1562500000000000000, i64 1953125000000000000, i64 1220703125000000000, i64 1525878906250000000, i64 1907348632812500000, i64 1192092895507812500, i64 1490116119384765625, i64 1862645149230957031, i64 1164153218269348144, i64 1455191522836685180, i64 1818989403545856475, i64 2273736754432320594, i64 1421085471520200371, i64 1776356839400250464, i64 2220446049250313080, i64 1387778780781445675, i64 1734723475976807094, i64 2168404344971008868, i64 1355252715606880542, i64 1694065894508600678, i64 2117582368135750847, i64 1323488980084844279, i64 1654361225106055349, i64 2067951531382569187, i64 1292469707114105741, i64 1615587133892632177, i64 2019483917365790221], align 16
 @strNaN = private unnamed_addr constant [3 x i32] [i32 110, i32 97, i32 110], align 4
@@ -20,7 +25,26 @@ source_filename = "llvm-link"
 @.strFree = private unnamed_addr constant [17 x i32] [i32 70, i32 114, i32 101, i32 101, i32 100, i32 32, i32 102, i32 114, i32 111, i32 109, i32 32, i32 109, i32 101, i32 109, i32 111, i32 114, i32 121], align 4
 @.strCount = private unnamed_addr constant [13 x i32] [i32 32, i32 114, i32 101, i32 102, i32 101, i32 114, i32 101, i32 110, i32 99, i32 101, i32 40, i32 115, i32 41], align 4
 @.strZero = private unnamed_addr constant [1 x i32] [i32 48], align 4
-@.strZero.13 = private unnamed_addr constant [1 x i32] [i32 48], align 4
+@.zero.map = private unnamed_addr constant [32 x i8] zeroinitializer, align 8
+@.strZero.16 = private unnamed_addr constant [1 x i32] [i32 48], align 4
+
+define fastcc i32 @".hash:bool"(i8* %key) {
+entry:
+  %0 = bitcast i8* %key to i1*
+  %1 = load i1, i1* %0, align 1
+  %2 = zext i1 %1 to i32
+  ret i32 %2
+}
+
+define fastcc i1 @".equal:bool"(i8* %a, i8* %b) {
+entry:
+  %0 = bitcast i8* %a to i1*
+  %1 = load i1, i1* %0, align 1
+  %2 = bitcast i8* %b to i1*
+  %3 = load i1, i1* %2, align 1
+  %4 = icmp eq i1 %1, %3
+  ret i1 %4
+}
 
 define fastcc %ref.bool* @"newref:bool"(i1 %bool) {
 entry:
@@ -111,6 +135,78 @@ exit:                                             ; preds = %if.else, %if.then
   ret %type.string %6
 }
 
+; Function Attrs: cold noreturn
+define fastcc void @.outOfRange(i32 %idx, i32 %length) #0 {
+entry:
+  %0 = getelementptr inbounds [45 x i8], [45 x i8]* @.msg, i32 0, i32 0
+  %1 = call i32 (i32, i8*, ...) @dprintf(i32 2, i8* %0, i32 %idx, i32 %length)
+  call void @exit(i32 1)
+  unreachable
+}
+
+declare i32 @dprintf(i32, i8*, ...)
+
+; Function Attrs: noreturn
+declare void @exit(i32) #1
+
+define fastcc void @.bounds(i32 %idx, i32 %length) {
+entry:
+  %0 = icmp ult i32 %idx, %length
+  br i1 %0, label %ok, label %fail
+
+fail:                                             ; preds = %entry
+  call fastcc void @.outOfRange(i32 %idx, i32 %length)
+  unreachable
+
+ok:                                               ; preds = %entry
+  ret void
+}
+
+; Function Attrs: cold noreturn
+define fastcc void @.noElement(i8* %enum) #0 {
+entry:
+  %0 = getelementptr inbounds [33 x i8], [33 x i8]* @.msg.1, i32 0, i32 0
+  %1 = call i32 (i32, i8*, ...) @dprintf(i32 2, i8* %0, i8* %enum)
+  call void @exit(i32 1)
+  unreachable
+}
+
+define fastcc i32 @.element(i32 %idx, i32 %length, i8* %enum) {
+entry:
+  %0 = icmp ult i32 %idx, %length
+  br i1 %0, label %ok, label %fail
+
+fail:                                             ; preds = %entry
+  call fastcc void @.noElement(i8* %enum)
+  unreachable
+
+ok:                                               ; preds = %entry
+  ret i32 %idx
+}
+
+define fastcc i32 @".hash:float"(i8* %key) {
+entry:
+  %0 = bitcast i8* %key to float*
+  %1 = load float, float* %0, align 4
+  %2 = fcmp oeq float %1, 0.000000e+00
+  %3 = select i1 %2, float 0.000000e+00, float %1
+  %bits = alloca float, align 4
+  store float %3, float* %bits, align 4
+  %4 = bitcast float* %bits to i8*
+  %5 = call fastcc i32 @".hash:int"(i8* %4)
+  ret i32 %5
+}
+
+define fastcc i1 @".equal:float"(i8* %a, i8* %b) {
+entry:
+  %0 = bitcast i8* %a to float*
+  %1 = load float, float* %0, align 4
+  %2 = bitcast i8* %b to float*
+  %3 = load float, float* %2, align 4
+  %4 = fcmp oeq float %1, %3
+  ret i1 %4
+}
+
 define fastcc %ref.float* @"newref:float"(float %float) {
 entry:
   %float.addr = alloca float, align 4
@@ -276,7 +372,7 @@ return:                                           ; preds = %if.else20, %if.end,
 }
 
 ; Function Attrs: argmemonly nofree nounwind willreturn
-declare void @llvm.memcpy.p0i8.p0i8.i64(i8* noalias nocapture writeonly, i8* noalias nocapture readonly, i64, i1 immarg) #0
+declare void @llvm.memcpy.p0i8.p0i8.i64(i8* noalias nocapture writeonly, i8* noalias nocapture readonly, i64, i1 immarg) #2
 
 define private fastcc %type.string @normalString(float %num, i32 %bits) {
 entry:
@@ -1404,7 +1500,7 @@ entry:
   store i32* %0, i32** %3, align 8
   %4 = load %type.string, %type.string* %1, align 8
   %5 = call %type.string @".copy:string"(%type.string %4)
-  call void @.println(%type.string %5)
+  call void @".println:string"(%type.string %5)
   br label %exit
 
 exit:                                             ; preds = %entry
@@ -1420,7 +1516,7 @@ entry:
   %3 = getelementptr inbounds %type.string, %type.string* %1, i32 0, i32 1
   store i32* %0, i32** %3, align 8
   %4 = load %type.string, %type.string* %1, align 8
-  call void @.println(%type.string %4)
+  call void @".println:string"(%type.string %4)
   ret void
 }
 
@@ -1435,7 +1531,7 @@ entry:
   store i32* %2, i32** %5, align 8
   %6 = load %type.string, %type.string* %3, align 8
   %7 = call %type.string @".add:string_string"(%type.string %1, %type.string %6)
-  call void @.println(%type.string %7)
+  call void @".println:string"(%type.string %7)
   ret void
 }
 
@@ -1451,6 +1547,31 @@ entry:
   ret void
 }
 
+define fastcc i32 @".hash:int"(i8* %key) {
+entry:
+  %0 = bitcast i8* %key to i32*
+  %1 = load i32, i32* %0, align 4
+  %2 = lshr i32 %1, 16
+  %3 = xor i32 %1, %2
+  %4 = mul i32 %3, -2048144789
+  %5 = lshr i32 %4, 13
+  %6 = xor i32 %4, %5
+  %7 = mul i32 %6, -1028477387
+  %8 = lshr i32 %7, 16
+  %9 = xor i32 %7, %8
+  ret i32 %9
+}
+
+define fastcc i1 @".equal:int"(i8* %a, i8* %b) {
+entry:
+  %0 = bitcast i8* %a to i32*
+  %1 = load i32, i32* %0, align 4
+  %2 = bitcast i8* %b to i32*
+  %3 = load i32, i32* %2, align 4
+  %4 = icmp eq i32 %1, %3
+  ret i1 %4
+}
+
 define fastcc %ref.int* @"newref:int"(i32 %int) {
 entry:
   %int.addr = alloca i32, align 4
@@ -1635,7 +1756,473 @@ exit:                                             ; preds = %for.end, %if.then1
   ret %type.string %50
 }
 
-define fastcc void @.print(%type.string %str) {
+define fastcc void @".clear:list"(%type.list* %list) {
+entry:
+  %0 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 0
+  store i32 0, i32* %0, align 4
+  ret void
+}
+
+define fastcc %type.list* @".copy:list"(%type.list* %src) {
+entry:
+  %0 = getelementptr inbounds %type.list, %type.list* %src, i32 0, i32 0
+  %length = load i32, i32* %0, align 4
+  %1 = getelementptr inbounds %type.list, %type.list* %src, i32 0, i32 2
+  %size = load i32, i32* %1, align 4
+  %2 = getelementptr inbounds %type.list, %type.list* %src, i32 0, i32 3
+  %items = load i8*, i8** %2, align 8
+  %3 = call i8* @malloc(i32 24)
+  %list = bitcast i8* %3 to %type.list*
+  %4 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 0
+  store i32 %length, i32* %4, align 4
+  %5 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 1
+  store i32 %length, i32* %5, align 4
+  %6 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 2
+  store i32 %size, i32* %6, align 4
+  %7 = mul i32 %length, %size
+  %8 = call i8* @malloc(i32 %7)
+  call void @llvm.memcpy.p0i8.p0i8.i32(i8* %8, i8* %items, i32 %7, i1 false)
+  %9 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 3
+  store i8* %8, i8** %9, align 8
+  ret %type.list* %list
+}
+
+; Function Attrs: argmemonly nofree nounwind willreturn
+declare void @llvm.memcpy.p0i8.p0i8.i32(i8* noalias nocapture writeonly, i8* noalias nocapture readonly, i32, i1 immarg) #2
+
+define fastcc void @".free:list"(%type.list* %list) {
+entry:
+  %0 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 3
+  %1 = load i8*, i8** %0, align 8
+  call void @free(i8* %1)
+  %2 = bitcast %type.list* %list to i8*
+  call void @free(i8* %2)
+  call void @freeAutoMsg()
+  ret void
+}
+
+define fastcc i8* @".insert:list"(%type.list* %list, i32 %idx) {
+entry:
+  %0 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 0
+  %length = load i32, i32* %0, align 4
+  %1 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 1
+  %capacity = load i32, i32* %1, align 4
+  %2 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 2
+  %size = load i32, i32* %2, align 4
+  %3 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 3
+  %valid = icmp ule i32 %idx, %length
+  br i1 %valid, label %check, label %fail
+
+fail:                                             ; preds = %entry
+  call fastcc void @.outOfRange(i32 %idx, i32 %length)
+  unreachable
+
+check:                                            ; preds = %entry
+  %4 = icmp eq i32 %length, %capacity
+  br i1 %4, label %grow, label %shift
+
+grow:                                             ; preds = %check
+  %5 = icmp eq i32 %capacity, 0
+  %6 = shl i32 %capacity, 1
+  %7 = select i1 %5, i32 4, i32 %6
+  store i32 %7, i32* %1, align 4
+  %8 = mul i32 %7, %size
+  %9 = load i8*, i8** %3, align 8
+  %10 = call i8* @realloc(i8* %9, i32 %8)
+  store i8* %10, i8** %3, align 8
+  br label %shift
+
+shift:                                            ; preds = %grow, %check
+  %items = load i8*, i8** %3, align 8
+  %11 = mul i32 %idx, %size
+  %slot = getelementptr inbounds i8, i8* %items, i32 %11
+  %12 = getelementptr inbounds i8, i8* %slot, i32 %size
+  %13 = sub i32 %length, %idx
+  %14 = mul i32 %13, %size
+  call void @llvm.memmove.p0i8.p0i8.i32(i8* %12, i8* %slot, i32 %14, i1 false)
+  %15 = add i32 %length, 1
+  store i32 %15, i32* %0, align 4
+  ret i8* %slot
+}
+
+declare i8* @realloc(i8*, i32)
+
+; Function Attrs: argmemonly nofree nounwind willreturn
+declare void @llvm.memmove.p0i8.p0i8.i32(i8* nocapture writeonly, i8* nocapture readonly, i32, i1 immarg) #2
+
+define fastcc %type.list* @".new:list"(i32 %size) {
+entry:
+  %0 = call i8* @malloc(i32 24)
+  %list = bitcast i8* %0 to %type.list*
+  %1 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 0
+  store i32 0, i32* %1, align 4
+  %2 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 1
+  store i32 0, i32* %2, align 4
+  %3 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 2
+  store i32 %size, i32* %3, align 4
+  %4 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 3
+  store i8* null, i8** %4, align 8
+  ret %type.list* %list
+}
+
+define fastcc void @".remove:list"(%type.list* %list, i32 %idx) {
+entry:
+  %0 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 0
+  %length = load i32, i32* %0, align 4
+  %1 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 2
+  %size = load i32, i32* %1, align 4
+  %2 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 3
+  %items = load i8*, i8** %2, align 8
+  %3 = mul i32 %idx, %size
+  %slot = getelementptr inbounds i8, i8* %items, i32 %3
+  %4 = getelementptr inbounds i8, i8* %slot, i32 %size
+  %5 = sub i32 %length, %idx
+  %6 = sub i32 %5, 1
+  %7 = mul i32 %6, %size
+  call void @llvm.memmove.p0i8.p0i8.i32(i8* %slot, i8* %4, i32 %7, i1 false)
+  %8 = sub i32 %length, 1
+  store i32 %8, i32* %0, align 4
+  ret void
+}
+
+define fastcc %type.map* @".copy:map"(%type.map* %src) {
+entry:
+  %0 = call i8* @malloc(i32 64)
+  %1 = bitcast %type.map* %src to i8*
+  call void @llvm.memcpy.p0i8.p0i8.i32(i8* %0, i8* %1, i32 64, i1 false)
+  %map = bitcast i8* %0 to %type.map*
+  %2 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 1
+  %capacity = load i32, i32* %2, align 4
+  %3 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 3
+  %ksize = load i32, i32* %3, align 4
+  %4 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 4
+  %vsize = load i32, i32* %4, align 4
+  %5 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 5
+  %6 = mul i32 %capacity, %ksize
+  call fastcc void @dup(i8** %5, i32 %6)
+  %7 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 6
+  %8 = mul i32 %capacity, %vsize
+  call fastcc void @dup(i8** %7, i32 %8)
+  %9 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 7
+  call fastcc void @dup(i8** %9, i32 %capacity)
+  ret %type.map* %map
+}
+
+define private fastcc void @dup(i8** %field, i32 %size) {
+entry:
+  %0 = load i8*, i8** %field, align 8
+  %1 = call i8* @malloc(i32 %size)
+  call void @llvm.memcpy.p0i8.p0i8.i32(i8* %1, i8* %0, i32 %size, i1 false)
+  store i8* %1, i8** %field, align 8
+  ret void
+}
+
+define fastcc void @".delete:map"(%type.map* %map, i32 %idx) {
+entry:
+  %0 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 7
+  %states = load i8*, i8** %0, align 8
+  %1 = getelementptr inbounds i8, i8* %states, i32 %idx
+  store i8 2, i8* %1, align 1
+  %2 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 0
+  %length = load i32, i32* %2, align 4
+  %3 = sub i32 %length, 1
+  store i32 %3, i32* %2, align 4
+  ret void
+}
+
+define fastcc i32 @".find:map"(%type.map* %map, i8* %key) {
+entry:
+  %0 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 1
+  %capacity = load i32, i32* %0, align 4
+  %1 = icmp eq i32 %capacity, 0
+  br i1 %1, label %missing, label %start
+
+start:                                            ; preds = %entry
+  %2 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 8
+  %hash = load i32 (i8*)*, i32 (i8*)** %2, align 8
+  %3 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 9
+  %equal = load i1 (i8*, i8*)*, i1 (i8*, i8*)** %3, align 8
+  %4 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 3
+  %ksize = load i32, i32* %4, align 4
+  %5 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 5
+  %keys = load i8*, i8** %5, align 8
+  %6 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 7
+  %states = load i8*, i8** %6, align 8
+  %mask = sub i32 %capacity, 1
+  %7 = call fastcc i32 %hash(i8* %key)
+  %first = and i32 %7, %mask
+  br label %probe
+
+probe:                                            ; preds = %skip, %start
+  %i = phi i32 [ %first, %start ], [ %next, %skip ]
+  %8 = getelementptr inbounds i8, i8* %states, i32 %i
+  %state = load i8, i8* %8, align 1
+  %9 = icmp eq i8 %state, 0
+  br i1 %9, label %missing, label %check
+
+check:                                            ; preds = %probe
+  %10 = icmp eq i8 %state, 1
+  br i1 %10, label %compare, label %skip
+
+compare:                                          ; preds = %check
+  %11 = mul i32 %i, %ksize
+  %12 = getelementptr inbounds i8, i8* %keys, i32 %11
+  %13 = call fastcc i1 %equal(i8* %12, i8* %key)
+  br i1 %13, label %found, label %skip
+
+found:                                            ; preds = %compare
+  ret i32 %i
+
+skip:                                             ; preds = %compare, %check
+  %14 = add i32 %i, 1
+  %next = and i32 %14, %mask
+  br label %probe
+
+missing:                                          ; preds = %probe, %entry
+  ret i32 -1
+}
+
+define fastcc void @".free:map"(%type.map* %map) {
+entry:
+  %0 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 5
+  %1 = load i8*, i8** %0, align 8
+  call void @free(i8* %1)
+  %2 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 6
+  %3 = load i8*, i8** %2, align 8
+  call void @free(i8* %3)
+  %4 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 7
+  %5 = load i8*, i8** %4, align 8
+  call void @free(i8* %5)
+  %6 = bitcast %type.map* %map to i8*
+  call void @free(i8* %6)
+  call void @freeAutoMsg()
+  ret void
+}
+
+define fastcc i8* @".get:map"(%type.map* %map, i8* %key) {
+entry:
+  %idx = call fastcc i32 @".find:map"(%type.map* %map, i8* %key)
+  %0 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 4
+  %vsize = load i32, i32* %0, align 4
+  %1 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 6
+  %vals = load i8*, i8** %1, align 8
+  %2 = mul i32 %idx, %vsize
+  %3 = getelementptr i8, i8* %vals, i32 %2
+  %4 = icmp eq i32 %idx, -1
+  %5 = select i1 %4, i8* getelementptr inbounds ([32 x i8], [32 x i8]* @.zero.map, i32 0, i32 0), i8* %3
+  ret i8* %5
+}
+
+define fastcc i32 @".insert:map"(%type.map* %map, i8* %key) {
+entry:
+  %0 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 1
+  %capacity = load i32, i32* %0, align 4
+  %1 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 2
+  %occupied = load i32, i32* %1, align 4
+  %2 = add i32 %occupied, 1
+  %3 = mul i32 %2, 4
+  %4 = mul i32 %capacity, 3
+  %5 = icmp ugt i32 %3, %4
+  br i1 %5, label %grow, label %place
+
+grow:                                             ; preds = %entry
+  call fastcc void @grow(%type.map* %map)
+  br label %place
+
+place:                                            ; preds = %grow, %entry
+  %6 = call fastcc i32 @free_slot(%type.map* %map, i8* %key)
+  %7 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 7
+  %states = load i8*, i8** %7, align 8
+  %8 = getelementptr inbounds i8, i8* %states, i32 %6
+  %state = load i8, i8* %8, align 1
+  store i8 1, i8* %8, align 1
+  %9 = load i32, i32* %1, align 4
+  %10 = icmp eq i8 %state, 0
+  %11 = zext i1 %10 to i32
+  %12 = add i32 %9, %11
+  store i32 %12, i32* %1, align 4
+  %13 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 3
+  %ksize = load i32, i32* %13, align 4
+  %14 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 5
+  %keys = load i8*, i8** %14, align 8
+  %15 = mul i32 %6, %ksize
+  %16 = getelementptr inbounds i8, i8* %keys, i32 %15
+  call void @llvm.memcpy.p0i8.p0i8.i32(i8* %16, i8* %key, i32 %ksize, i1 false)
+  %17 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 4
+  %vsize = load i32, i32* %17, align 4
+  %18 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 6
+  %vals = load i8*, i8** %18, align 8
+  %19 = mul i32 %6, %vsize
+  %20 = getelementptr inbounds i8, i8* %vals, i32 %19
+  call void @llvm.memset.p0i8.i32(i8* %20, i8 0, i32 %vsize, i1 false)
+  %21 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 0
+  %length = load i32, i32* %21, align 4
+  %22 = add i32 %length, 1
+  store i32 %22, i32* %21, align 4
+  ret i32 %6
+}
+
+define private fastcc void @grow(%type.map* %map) {
+entry:
+  %0 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 1
+  %capacity = load i32, i32* %0, align 4
+  %1 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 3
+  %ksize = load i32, i32* %1, align 4
+  %2 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 4
+  %vsize = load i32, i32* %2, align 4
+  %3 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 5
+  %keys = load i8*, i8** %3, align 8
+  %4 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 6
+  %vals = load i8*, i8** %4, align 8
+  %5 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 7
+  %states = load i8*, i8** %5, align 8
+  %6 = icmp eq i32 %capacity, 0
+  %7 = shl i32 %capacity, 1
+  %size = select i1 %6, i32 8, i32 %7
+  %8 = mul i32 %size, %ksize
+  %nkeys = call i8* @malloc(i32 %8)
+  %9 = mul i32 %size, %vsize
+  %nvals = call i8* @malloc(i32 %9)
+  %nstates = call i8* @malloc(i32 %size)
+  call void @llvm.memset.p0i8.i32(i8* %nstates, i8 0, i32 %size, i1 false)
+  store i32 %size, i32* %0, align 4
+  store i8* %nkeys, i8** %3, align 8
+  store i8* %nvals, i8** %4, align 8
+  store i8* %nstates, i8** %5, align 8
+  %10 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 0
+  %length = load i32, i32* %10, align 4
+  %11 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 2
+  store i32 %length, i32* %11, align 4
+  br label %cond
+
+cond:                                             ; preds = %skip, %entry
+  %j = phi i32 [ 0, %entry ], [ %next, %skip ]
+  %12 = icmp slt i32 %j, %capacity
+  br i1 %12, label %check, label %end
+
+check:                                            ; preds = %cond
+  %13 = getelementptr inbounds i8, i8* %states, i32 %j
+  %14 = load i8, i8* %13, align 1
+  %15 = icmp eq i8 %14, 1
+  br i1 %15, label %move, label %skip
+
+move:                                             ; preds = %check
+  %16 = mul i32 %j, %ksize
+  %key = getelementptr inbounds i8, i8* %keys, i32 %16
+  %i = call fastcc i32 @free_slot(%type.map* %map, i8* %key)
+  %17 = getelementptr inbounds i8, i8* %nstates, i32 %i
+  store i8 1, i8* %17, align 1
+  %18 = mul i32 %i, %ksize
+  %19 = getelementptr inbounds i8, i8* %nkeys, i32 %18
+  call void @llvm.memcpy.p0i8.p0i8.i32(i8* %19, i8* %key, i32 %ksize, i1 false)
+  %20 = mul i32 %j, %vsize
+  %21 = getelementptr inbounds i8, i8* %vals, i32 %20
+  %22 = mul i32 %i, %vsize
+  %23 = getelementptr inbounds i8, i8* %nvals, i32 %22
+  call void @llvm.memcpy.p0i8.p0i8.i32(i8* %23, i8* %21, i32 %vsize, i1 false)
+  br label %skip
+
+skip:                                             ; preds = %move, %check
+  %next = add i32 %j, 1
+  br label %cond
+
+end:                                              ; preds = %cond
+  call void @free(i8* %keys)
+  call void @free(i8* %vals)
+  call void @free(i8* %states)
+  ret void
+}
+
+define private fastcc i32 @free_slot(%type.map* %map, i8* %key) {
+entry:
+  %0 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 1
+  %capacity = load i32, i32* %0, align 4
+  %1 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 7
+  %states = load i8*, i8** %1, align 8
+  %2 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 8
+  %hash = load i32 (i8*)*, i32 (i8*)** %2, align 8
+  %mask = sub i32 %capacity, 1
+  %3 = call fastcc i32 %hash(i8* %key)
+  %first = and i32 %3, %mask
+  br label %probe
+
+probe:                                            ; preds = %skip, %entry
+  %i = phi i32 [ %first, %entry ], [ %next, %skip ]
+  %4 = getelementptr inbounds i8, i8* %states, i32 %i
+  %5 = load i8, i8* %4, align 1
+  %6 = icmp eq i8 %5, 1
+  br i1 %6, label %skip, label %found
+
+skip:                                             ; preds = %probe
+  %7 = add i32 %i, 1
+  %next = and i32 %7, %mask
+  br label %probe
+
+found:                                            ; preds = %probe
+  ret i32 %i
+}
+
+; Function Attrs: argmemonly nofree nounwind willreturn writeonly
+declare void @llvm.memset.p0i8.i32(i8* nocapture writeonly, i8, i32, i1 immarg) #3
+
+define fastcc %type.map* @".new:map"(i32 %ksize, i32 %vsize, i32 (i8*)* %hash, i1 (i8*, i8*)* %equal) {
+entry:
+  %0 = call i8* @malloc(i32 64)
+  %map = bitcast i8* %0 to %type.map*
+  %1 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 0
+  store i32 0, i32* %1, align 4
+  %2 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 1
+  store i32 0, i32* %2, align 4
+  %3 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 2
+  store i32 0, i32* %3, align 4
+  %4 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 3
+  store i32 %ksize, i32* %4, align 4
+  %5 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 4
+  store i32 %vsize, i32* %5, align 4
+  %6 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 5
+  store i8* null, i8** %6, align 8
+  %7 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 6
+  store i8* null, i8** %7, align 8
+  %8 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 7
+  store i8* null, i8** %8, align 8
+  %9 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 8
+  store i32 (i8*)* %hash, i32 (i8*)** %9, align 8
+  %10 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 9
+  store i1 (i8*, i8*)* %equal, i1 (i8*, i8*)** %10, align 8
+  ret %type.map* %map
+}
+
+define fastcc i32 @".next:map"(%type.map* %map, i32 %from) {
+entry:
+  %0 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 1
+  %capacity = load i32, i32* %0, align 4
+  %1 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 7
+  %states = load i8*, i8** %1, align 8
+  br label %cond
+
+cond:                                             ; preds = %skip, %entry
+  %i = phi i32 [ %from, %entry ], [ %next, %skip ]
+  %2 = icmp slt i32 %i, %capacity
+  br i1 %2, label %check, label %end
+
+check:                                            ; preds = %cond
+  %3 = getelementptr inbounds i8, i8* %states, i32 %i
+  %4 = load i8, i8* %3, align 1
+  %5 = icmp eq i8 %4, 1
+  br i1 %5, label %found, label %skip
+
+found:                                            ; preds = %check
+  ret i32 %i
+
+skip:                                             ; preds = %check
+  %next = add i32 %i, 1
+  br label %cond
+
+end:                                              ; preds = %cond
+  ret i32 -1
+}
+
+define fastcc void @".print:string"(%type.string %str) {
 entry:
   %ptr.str = alloca %type.string, align 8
   store %type.string %str, %type.string* %ptr.str, align 8
@@ -1752,9 +2339,46 @@ if.end35:                                         ; preds = %if.end34, %if.then
 
 declare void @putchar(i32)
 
-define fastcc void @.println(%type.string %str) {
+define fastcc void @".println:string"(%type.string %str) {
 entry:
-  call void @.print(%type.string %str)
+  call fastcc void @".print:string"(%type.string %str)
+  call void @putchar(i32 10)
+  ret void
+}
+
+define fastcc void @.print(%"type.string[]" %strs) {
+entry:
+  %length = extractvalue %"type.string[]" %strs, 0
+  %addr = extractvalue %"type.string[]" %strs, 1
+  br label %for.cond
+
+for.cond:                                         ; preds = %for.print, %entry
+  %i = phi i32 [ 0, %entry ], [ %next, %for.print ]
+  %more = icmp slt i32 %i, %length
+  br i1 %more, label %for.body, label %for.exit
+
+for.body:                                         ; preds = %for.cond
+  %gap = icmp sgt i32 %i, 0
+  br i1 %gap, label %for.space, label %for.print
+
+for.space:                                        ; preds = %for.body
+  call void @putchar(i32 32)
+  br label %for.print
+
+for.print:                                        ; preds = %for.space, %for.body
+  %ptr = getelementptr inbounds %type.string, %type.string* %addr, i32 %i
+  %str = load %type.string, %type.string* %ptr, align 8
+  call fastcc void @".print:string"(%type.string %str)
+  %next = add i32 %i, 1
+  br label %for.cond
+
+for.exit:                                         ; preds = %for.cond
+  ret void
+}
+
+define fastcc void @.println(%"type.string[]" %strs) {
+entry:
+  call fastcc void @.print(%"type.string[]" %strs)
   call void @putchar(i32 10)
   ret void
 }
@@ -1794,7 +2418,7 @@ entry:
 }
 
 ; Function Attrs: argmemonly nofree nounwind willreturn
-declare void @llvm.memcpy.p0i32.p0i32.i32(i32* noalias nocapture writeonly, i32* noalias nocapture readonly, i32, i1 immarg) #0
+declare void @llvm.memcpy.p0i32.p0i32.i32(i32* noalias nocapture writeonly, i32* noalias nocapture readonly, i32, i1 immarg) #2
 
 define fastcc %type.string @".copy:string"(%type.string %str) {
 entry:
@@ -1817,6 +2441,62 @@ entry:
   ret %type.string %9
 }
 
+define fastcc i32 @".hash:string"(i8* %key) {
+entry:
+  %0 = bitcast i8* %key to %type.string*
+  %1 = getelementptr inbounds %type.string, %type.string* %0, i32 0, i32 0
+  %length = load i32, i32* %1, align 4
+  %2 = getelementptr inbounds %type.string, %type.string* %0, i32 0, i32 1
+  %chars = load i32*, i32** %2, align 8
+  br label %cond
+
+cond:                                             ; preds = %loop, %entry
+  %i = phi i32 [ 0, %entry ], [ %next, %loop ]
+  %hash = phi i32 [ -2128831035, %entry ], [ %5, %loop ]
+  %3 = icmp slt i32 %i, %length
+  br i1 %3, label %loop, label %end
+
+loop:                                             ; preds = %cond
+  %4 = getelementptr inbounds i32, i32* %chars, i32 %i
+  %char = load i32, i32* %4, align 4
+  %mixed = xor i32 %hash, %char
+  %5 = mul i32 %mixed, 16777619
+  %next = add i32 %i, 1
+  br label %cond
+
+end:                                              ; preds = %cond
+  ret i32 %hash
+}
+
+define fastcc i1 @".equal:string"(i8* %a, i8* %b) {
+entry:
+  %0 = bitcast i8* %a to %type.string*
+  %1 = load %type.string, %type.string* %0, align 8
+  %2 = bitcast i8* %b to %type.string*
+  %3 = load %type.string, %type.string* %2, align 8
+  %4 = extractvalue %type.string %1, 0
+  %5 = extractvalue %type.string %3, 0
+  %6 = icmp eq i32 %4, %5
+  br i1 %6, label %compare, label %end
+
+compare:                                          ; preds = %entry
+  %7 = extractvalue %type.string %1, 1
+  %8 = bitcast i32* %7 to i8*
+  %9 = extractvalue %type.string %3, 1
+  %10 = bitcast i32* %9 to i8*
+  %11 = mul i32 %4, 4
+  %12 = zext i32 %11 to i64
+  %13 = call i32 @memcmp(i8* %8, i8* %10, i64 %12)
+  %14 = icmp eq i32 %13, 0
+  br label %end
+
+end:                                              ; preds = %compare, %entry
+  %15 = phi i1 [ false, %entry ], [ %14, %compare ]
+  ret i1 %15
+}
+
+declare i32 @memcmp(i8*, i8*, i64)
+
 define fastcc %ref.int* @"newref:uint"(i32 %uint) {
 entry:
   %uint.addr = alloca i32, align 4
@@ -1892,7 +2572,7 @@ if.then1:                                         ; preds = %entry
   %1 = getelementptr inbounds %type.string, %type.string* %.ret, i32 0, i32 0
   store i32 1, i32* %1, align 8
   %2 = getelementptr inbounds %type.string, %type.string* %.ret, i32 0, i32 1
-  %3 = getelementptr inbounds [1 x i32], [1 x i32]* @.strZero.13, i32 0, i32 0
+  %3 = getelementptr inbounds [1 x i32], [1 x i32]* @.strZero.16, i32 0, i32 0
   store i32* %3, i32** %2, align 8
   br label %exit
 
@@ -1971,4 +2651,7 @@ exit:                                             ; preds = %for.cond, %if.then1
   ret %type.string %40
 }
 
-attributes #0 = { argmemonly nofree nounwind willreturn }
+attributes #0 = { cold noreturn }
+attributes #1 = { noreturn }
+attributes #2 = { argmemonly nofree nounwind willreturn }
+attributes #3 = { argmemonly nofree nounwind willreturn writeonly }
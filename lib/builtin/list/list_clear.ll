@@ -0,0 +1,11 @@
+source_filename = "lib/builtin/list/list_clear.ll"
+
+%type.list = type { i32, i32, i32, i8* }
+
+; Keeps the capacity, so a cleared list can be refilled without growing again
+define fastcc void @".clear:list"(%type.list* %list) {
+entry:
+    %0 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 0
+    store i32 0, i32* %0, align 4
+    ret void
+}
@@ -0,0 +1,32 @@
+source_filename = "lib/builtin/list/list_copy.ll"
+
+%type.list = type { i32, i32, i32, i8* }
+
+declare i8* @malloc(i32)
+
+declare void @llvm.memcpy.p0i8.p0i8.i32(i8* noalias nocapture writeonly, i8* noalias nocapture readonly, i32, i1 immarg)
+
+; Copies the items bit by bit, so anything they own has to be copied afterwards
+define fastcc %type.list* @".copy:list"(%type.list* %src) {
+entry:
+    %0 = getelementptr inbounds %type.list, %type.list* %src, i32 0, i32 0
+    %length = load i32, i32* %0, align 4
+    %1 = getelementptr inbounds %type.list, %type.list* %src, i32 0, i32 2
+    %size = load i32, i32* %1, align 4
+    %2 = getelementptr inbounds %type.list, %type.list* %src, i32 0, i32 3
+    %items = load i8*, i8** %2, align 8
+    %3 = call i8* @malloc(i32 24)
+    %list = bitcast i8* %3 to %type.list*
+    %4 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 0
+    store i32 %length, i32* %4, align 4
+    %5 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 1
+    store i32 %length, i32* %5, align 4
+    %6 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 2
+    store i32 %size, i32* %6, align 4
+    %7 = mul i32 %length, %size
+    %8 = call i8* @malloc(i32 %7)
+    call void @llvm.memcpy.p0i8.p0i8.i32(i8* %8, i8* %items, i32 %7, i1 false)
+    %9 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 3
+    store i8* %8, i8** %9, align 8
+    ret %type.list* %list
+}
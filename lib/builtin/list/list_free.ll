@@ -0,0 +1,18 @@
+source_filename = "lib/builtin/list/list_free.ll"
+
+%type.list = type { i32, i32, i32, i8* }
+
+declare void @free(i8*)
+
+declare fastcc void @freeAutoMsg()
+
+define fastcc void @".free:list"(%type.list* %list) {
+entry:
+    %0 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 3
+    %1 = load i8*, i8** %0, align 8
+    call void @free(i8* %1)
+    %2 = bitcast %type.list* %list to i8*
+    call void @free(i8* %2)
+    call void @freeAutoMsg()
+    ret void
+}
@@ -0,0 +1,54 @@
+source_filename = "lib/builtin/list/list_insert.ll"
+
+%type.list = type { i32, i32, i32, i8* }
+
+declare i8* @realloc(i8*, i32)
+
+declare void @llvm.memmove.p0i8.p0i8.i32(i8* nocapture writeonly, i8* nocapture readonly, i32, i1 immarg)
+
+declare fastcc void @.outOfRange(i32, i32)
+
+; Makes room for an item at the index, which may be one past the last item, doubling the capacity when the list is full
+define fastcc i8* @".insert:list"(%type.list* %list, i32 %idx) {
+entry:
+    %0 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 0
+    %length = load i32, i32* %0, align 4
+    %1 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 1
+    %capacity = load i32, i32* %1, align 4
+    %2 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 2
+    %size = load i32, i32* %2, align 4
+    %3 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 3
+    %valid = icmp ule i32 %idx, %length
+    br i1 %valid, label %check, label %fail
+
+fail:
+    call fastcc void @.outOfRange(i32 %idx, i32 %length)
+    unreachable
+
+check:
+    %4 = icmp eq i32 %length, %capacity
+    br i1 %4, label %grow, label %shift
+
+grow:
+    %5 = icmp eq i32 %capacity, 0
+    %6 = shl i32 %capacity, 1
+    %7 = select i1 %5, i32 4, i32 %6
+    store i32 %7, i32* %1, align 4
+    %8 = mul i32 %7, %size
+    %9 = load i8*, i8** %3, align 8
+    %10 = call i8* @realloc(i8* %9, i32 %8)
+    store i8* %10, i8** %3, align 8
+    br label %shift
+
+shift:
+    %items = load i8*, i8** %3, align 8
+    %11 = mul i32 %idx, %size
+    %slot = getelementptr inbounds i8, i8* %items, i32 %11
+    %12 = getelementptr inbounds i8, i8* %slot, i32 %size
+    %13 = sub i32 %length, %idx
+    %14 = mul i32 %13, %size
+    call void @llvm.memmove.p0i8.p0i8.i32(i8* %12, i8* %slot, i32 %14, i1 false)
+    %15 = add i32 %length, 1
+    store i32 %15, i32* %0, align 4
+    ret i8* %slot
+}
@@ -0,0 +1,20 @@
+source_filename = "lib/builtin/list/list_new.ll"
+
+%type.list = type { i32, i32, i32, i8* }
+
+declare i8* @malloc(i32)
+
+define fastcc %type.list* @".new:list"(i32 %size) {
+entry:
+    %0 = call i8* @malloc(i32 24)
+    %list = bitcast i8* %0 to %type.list*
+    %1 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 0
+    store i32 0, i32* %1, align 4
+    %2 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 1
+    store i32 0, i32* %2, align 4
+    %3 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 2
+    store i32 %size, i32* %3, align 4
+    %4 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 3
+    store i8* null, i8** %4, align 8
+    ret %type.list* %list
+}
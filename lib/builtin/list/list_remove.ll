@@ -0,0 +1,26 @@
+source_filename = "lib/builtin/list/list_remove.ll"
+
+%type.list = type { i32, i32, i32, i8* }
+
+declare void @llvm.memmove.p0i8.p0i8.i32(i8* nocapture writeonly, i8* nocapture readonly, i32, i1 immarg)
+
+; Closes the gap left by the item at the index, which has to be read or freed beforehand
+define fastcc void @".remove:list"(%type.list* %list, i32 %idx) {
+entry:
+    %0 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 0
+    %length = load i32, i32* %0, align 4
+    %1 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 2
+    %size = load i32, i32* %1, align 4
+    %2 = getelementptr inbounds %type.list, %type.list* %list, i32 0, i32 3
+    %items = load i8*, i8** %2, align 8
+    %3 = mul i32 %idx, %size
+    %slot = getelementptr inbounds i8, i8* %items, i32 %3
+    %4 = getelementptr inbounds i8, i8* %slot, i32 %size
+    %5 = sub i32 %length, %idx
+    %6 = sub i32 %5, 1
+    %7 = mul i32 %6, %size
+    call void @llvm.memmove.p0i8.p0i8.i32(i8* %slot, i8* %4, i32 %7, i1 false)
+    %8 = sub i32 %length, 1
+    store i32 %8, i32* %0, align 4
+    ret void
+}
@@ -0,0 +1,41 @@
+source_filename = "lib/builtin/map/map_copy.ll"
+
+%type.map = type { i32, i32, i32, i32, i32, i8*, i8*, i8*, i32 (i8*)*, i1 (i8*, i8*)* }
+
+declare i8* @malloc(i32)
+
+declare void @llvm.memcpy.p0i8.p0i8.i32(i8* noalias nocapture writeonly, i8* noalias nocapture readonly, i32, i1 immarg)
+
+; Copies the slots bit by bit, so anything the keys and values own has to be copied afterwards
+define fastcc %type.map* @".copy:map"(%type.map* %src) {
+entry:
+    %0 = call i8* @malloc(i32 64)
+    %1 = bitcast %type.map* %src to i8*
+    call void @llvm.memcpy.p0i8.p0i8.i32(i8* %0, i8* %1, i32 64, i1 false)
+    %map = bitcast i8* %0 to %type.map*
+    %2 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 1
+    %capacity = load i32, i32* %2, align 4
+    %3 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 3
+    %ksize = load i32, i32* %3, align 4
+    %4 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 4
+    %vsize = load i32, i32* %4, align 4
+    %5 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 5
+    %6 = mul i32 %capacity, %ksize
+    call fastcc void @dup(i8** %5, i32 %6)
+    %7 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 6
+    %8 = mul i32 %capacity, %vsize
+    call fastcc void @dup(i8** %7, i32 %8)
+    %9 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 7
+    call fastcc void @dup(i8** %9, i32 %capacity)
+    ret %type.map* %map
+}
+
+; Replaces the memory a field points to with a copy of it
+define private fastcc void @dup(i8** %field, i32 %size) {
+entry:
+    %0 = load i8*, i8** %field, align 8
+    %1 = call i8* @malloc(i32 %size)
+    call void @llvm.memcpy.p0i8.p0i8.i32(i8* %1, i8* %0, i32 %size, i1 false)
+    store i8* %1, i8** %field, align 8
+    ret void
+}
@@ -0,0 +1,17 @@
+source_filename = "lib/builtin/map/map_delete.ll"
+
+%type.map = type { i32, i32, i32, i32, i32, i8*, i8*, i8*, i32 (i8*)*, i1 (i8*, i8*)* }
+
+; Leaves a tombstone in the slot, so that probing continues past it
+define fastcc void @".delete:map"(%type.map* %map, i32 %idx) {
+entry:
+    %0 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 7
+    %states = load i8*, i8** %0, align 8
+    %1 = getelementptr inbounds i8, i8* %states, i32 %idx
+    store i8 2, i8* %1, align 1
+    %2 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 0
+    %length = load i32, i32* %2, align 4
+    %3 = sub i32 %length, 1
+    store i32 %3, i32* %2, align 4
+    ret void
+}
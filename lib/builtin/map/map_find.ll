@@ -0,0 +1,56 @@
+source_filename = "lib/builtin/map/map_find.ll"
+
+%type.map = type { i32, i32, i32, i32, i32, i8*, i8*, i8*, i32 (i8*)*, i1 (i8*, i8*)* }
+
+; Finds the slot of a key by linear probing, or gives -1 when the map does not have it
+define fastcc i32 @".find:map"(%type.map* %map, i8* %key) {
+entry:
+    %0 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 1
+    %capacity = load i32, i32* %0, align 4
+    %1 = icmp eq i32 %capacity, 0
+    br i1 %1, label %missing, label %start
+
+start:
+    %2 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 8
+    %hash = load i32 (i8*)*, i32 (i8*)** %2, align 8
+    %3 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 9
+    %equal = load i1 (i8*, i8*)*, i1 (i8*, i8*)** %3, align 8
+    %4 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 3
+    %ksize = load i32, i32* %4, align 4
+    %5 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 5
+    %keys = load i8*, i8** %5, align 8
+    %6 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 7
+    %states = load i8*, i8** %6, align 8
+    %mask = sub i32 %capacity, 1
+    %7 = call fastcc i32 %hash(i8* %key)
+    %first = and i32 %7, %mask
+    br label %probe
+
+probe:
+    %i = phi i32 [ %first, %start ], [ %next, %skip ]
+    %8 = getelementptr inbounds i8, i8* %states, i32 %i
+    %state = load i8, i8* %8, align 1
+    %9 = icmp eq i8 %state, 0
+    br i1 %9, label %missing, label %check
+
+check:
+    %10 = icmp eq i8 %state, 1
+    br i1 %10, label %compare, label %skip
+
+compare:
+    %11 = mul i32 %i, %ksize
+    %12 = getelementptr inbounds i8, i8* %keys, i32 %11
+    %13 = call fastcc i1 %equal(i8* %12, i8* %key)
+    br i1 %13, label %found, label %skip
+
+found:
+    ret i32 %i
+
+skip:
+    %14 = add i32 %i, 1
+    %next = and i32 %14, %mask
+    br label %probe
+
+missing:
+    ret i32 -1
+}
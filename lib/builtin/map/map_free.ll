@@ -0,0 +1,24 @@
+source_filename = "lib/builtin/map/map_free.ll"
+
+%type.map = type { i32, i32, i32, i32, i32, i8*, i8*, i8*, i32 (i8*)*, i1 (i8*, i8*)* }
+
+declare void @free(i8*)
+
+declare fastcc void @freeAutoMsg()
+
+define fastcc void @".free:map"(%type.map* %map) {
+entry:
+    %0 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 5
+    %1 = load i8*, i8** %0, align 8
+    call void @free(i8* %1)
+    %2 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 6
+    %3 = load i8*, i8** %2, align 8
+    call void @free(i8* %3)
+    %4 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 7
+    %5 = load i8*, i8** %4, align 8
+    call void @free(i8* %5)
+    %6 = bitcast %type.map* %map to i8*
+    call void @free(i8* %6)
+    call void @freeAutoMsg()
+    ret void
+}
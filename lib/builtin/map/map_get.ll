@@ -0,0 +1,22 @@
+source_filename = "lib/builtin/map/map_get.ll"
+
+%type.map = type { i32, i32, i32, i32, i32, i8*, i8*, i8*, i32 (i8*)*, i1 (i8*, i8*)* }
+
+@.zero.map = private unnamed_addr constant [32 x i8] zeroinitializer, align 8
+
+declare fastcc i32 @".find:map"(%type.map*, i8*)
+
+; Missing keys read as the zero value of the values, which is never written to
+define fastcc i8* @".get:map"(%type.map* %map, i8* %key) {
+entry:
+    %idx = call fastcc i32 @".find:map"(%type.map* %map, i8* %key)
+    %0 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 4
+    %vsize = load i32, i32* %0, align 4
+    %1 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 6
+    %vals = load i8*, i8** %1, align 8
+    %2 = mul i32 %idx, %vsize
+    %3 = getelementptr i8, i8* %vals, i32 %2
+    %4 = icmp eq i32 %idx, -1
+    %5 = select i1 %4, i8* getelementptr inbounds ([32 x i8], [32 x i8]* @.zero.map, i32 0, i32 0), i8* %3
+    ret i8* %5
+}
@@ -0,0 +1,172 @@
+source_filename = "lib/builtin/map/map_insert.ll"
+
+%type.map = type { i32, i32, i32, i32, i32, i8*, i8*, i8*, i32 (i8*)*, i1 (i8*, i8*)* }
+
+declare i8* @malloc(i32)
+
+declare void @free(i8*)
+
+declare void @llvm.memcpy.p0i8.p0i8.i32(i8* noalias nocapture writeonly, i8* noalias nocapture readonly, i32, i1 immarg)
+
+declare void @llvm.memset.p0i8.i32(i8* nocapture writeonly, i8, i32, i1 immarg)
+
+; Gives a slot to a key that the map does not have yet, with its value zeroed
+define fastcc i32 @".insert:map"(%type.map* %map, i8* %key) {
+entry:
+    %0 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 1
+    %capacity = load i32, i32* %0, align 4
+    %1 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 2
+    %occupied = load i32, i32* %1, align 4
+
+    ; Slots are kept at most three quarters occupied, tombstones included
+    %2 = add i32 %occupied, 1
+    %3 = mul i32 %2, 4
+    %4 = mul i32 %capacity, 3
+    %5 = icmp ugt i32 %3, %4
+    br i1 %5, label %grow, label %place
+
+grow:
+    call fastcc void @grow(%type.map* %map)
+    br label %place
+
+place:
+    %6 = call fastcc i32 @free_slot(%type.map* %map, i8* %key)
+    %7 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 7
+    %states = load i8*, i8** %7, align 8
+    %8 = getelementptr inbounds i8, i8* %states, i32 %6
+    %state = load i8, i8* %8, align 1
+    store i8 1, i8* %8, align 1
+
+    ; Reusing a tombstone does not occupy another slot
+    %9 = load i32, i32* %1, align 4
+    %10 = icmp eq i8 %state, 0
+    %11 = zext i1 %10 to i32
+    %12 = add i32 %9, %11
+    store i32 %12, i32* %1, align 4
+
+    %13 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 3
+    %ksize = load i32, i32* %13, align 4
+    %14 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 5
+    %keys = load i8*, i8** %14, align 8
+    %15 = mul i32 %6, %ksize
+    %16 = getelementptr inbounds i8, i8* %keys, i32 %15
+    call void @llvm.memcpy.p0i8.p0i8.i32(i8* %16, i8* %key, i32 %ksize, i1 false)
+
+    %17 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 4
+    %vsize = load i32, i32* %17, align 4
+    %18 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 6
+    %vals = load i8*, i8** %18, align 8
+    %19 = mul i32 %6, %vsize
+    %20 = getelementptr inbounds i8, i8* %vals, i32 %19
+    call void @llvm.memset.p0i8.i32(i8* %20, i8 0, i32 %vsize, i1 false)
+
+    %21 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 0
+    %length = load i32, i32* %21, align 4
+    %22 = add i32 %length, 1
+    store i32 %22, i32* %21, align 4
+    ret i32 %6
+}
+
+; Finds the first slot along the probe of a key that is not in use
+define private fastcc i32 @free_slot(%type.map* %map, i8* %key) {
+entry:
+    %0 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 1
+    %capacity = load i32, i32* %0, align 4
+    %1 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 7
+    %states = load i8*, i8** %1, align 8
+    %2 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 8
+    %hash = load i32 (i8*)*, i32 (i8*)** %2, align 8
+    %mask = sub i32 %capacity, 1
+    %3 = call fastcc i32 %hash(i8* %key)
+    %first = and i32 %3, %mask
+    br label %probe
+
+probe:
+    %i = phi i32 [ %first, %entry ], [ %next, %skip ]
+    %4 = getelementptr inbounds i8, i8* %states, i32 %i
+    %5 = load i8, i8* %4, align 1
+    %6 = icmp eq i8 %5, 1
+    br i1 %6, label %skip, label %found
+
+skip:
+    %7 = add i32 %i, 1
+    %next = and i32 %7, %mask
+    br label %probe
+
+found:
+    ret i32 %i
+}
+
+; Doubles the slots and moves every entry over, which also drops the tombstones
+define private fastcc void @grow(%type.map* %map) {
+entry:
+    %0 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 1
+    %capacity = load i32, i32* %0, align 4
+    %1 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 3
+    %ksize = load i32, i32* %1, align 4
+    %2 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 4
+    %vsize = load i32, i32* %2, align 4
+    %3 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 5
+    %keys = load i8*, i8** %3, align 8
+    %4 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 6
+    %vals = load i8*, i8** %4, align 8
+    %5 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 7
+    %states = load i8*, i8** %5, align 8
+
+    %6 = icmp eq i32 %capacity, 0
+    %7 = shl i32 %capacity, 1
+    %size = select i1 %6, i32 8, i32 %7
+    %8 = mul i32 %size, %ksize
+    %nkeys = call i8* @malloc(i32 %8)
+    %9 = mul i32 %size, %vsize
+    %nvals = call i8* @malloc(i32 %9)
+    %nstates = call i8* @malloc(i32 %size)
+    call void @llvm.memset.p0i8.i32(i8* %nstates, i8 0, i32 %size, i1 false)
+
+    store i32 %size, i32* %0, align 4
+    store i8* %nkeys, i8** %3, align 8
+    store i8* %nvals, i8** %4, align 8
+    store i8* %nstates, i8** %5, align 8
+    %10 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 0
+    %length = load i32, i32* %10, align 4
+    %11 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 2
+    store i32 %length, i32* %11, align 4
+    br label %cond
+
+cond:
+    %j = phi i32 [ 0, %entry ], [ %next, %skip ]
+    %12 = icmp slt i32 %j, %capacity
+    br i1 %12, label %check, label %end
+
+check:
+    %13 = getelementptr inbounds i8, i8* %states, i32 %j
+    %14 = load i8, i8* %13, align 1
+    %15 = icmp eq i8 %14, 1
+    br i1 %15, label %move, label %skip
+
+move:
+    %16 = mul i32 %j, %ksize
+    %key = getelementptr inbounds i8, i8* %keys, i32 %16
+    %i = call fastcc i32 @free_slot(%type.map* %map, i8* %key)
+    %17 = getelementptr inbounds i8, i8* %nstates, i32 %i
+    store i8 1, i8* %17, align 1
+    %18 = mul i32 %i, %ksize
+    %19 = getelementptr inbounds i8, i8* %nkeys, i32 %18
+    call void @llvm.memcpy.p0i8.p0i8.i32(i8* %19, i8* %key, i32 %ksize, i1 false)
+    %20 = mul i32 %j, %vsize
+    %21 = getelementptr inbounds i8, i8* %vals, i32 %20
+    %22 = mul i32 %i, %vsize
+    %23 = getelementptr inbounds i8, i8* %nvals, i32 %22
+    call void @llvm.memcpy.p0i8.p0i8.i32(i8* %23, i8* %21, i32 %vsize, i1 false)
+    br label %skip
+
+skip:
+    %next = add i32 %j, 1
+    br label %cond
+
+end:
+    call void @free(i8* %keys)
+    call void @free(i8* %vals)
+    call void @free(i8* %states)
+    ret void
+}
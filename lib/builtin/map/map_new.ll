@@ -0,0 +1,33 @@
+source_filename = "lib/builtin/map/map_new.ll"
+
+%type.map = type { i32, i32, i32, i32, i32, i8*, i8*, i8*, i32 (i8*)*, i1 (i8*, i8*)* }
+
+declare i8* @malloc(i32)
+
+; Maps start without any slots, which are only allocated by the first insertion
+define fastcc %type.map* @".new:map"(i32 %ksize, i32 %vsize, i32 (i8*)* %hash, i1 (i8*, i8*)* %equal) {
+entry:
+    %0 = call i8* @malloc(i32 64)
+    %map = bitcast i8* %0 to %type.map*
+    %1 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 0
+    store i32 0, i32* %1, align 4
+    %2 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 1
+    store i32 0, i32* %2, align 4
+    %3 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 2
+    store i32 0, i32* %3, align 4
+    %4 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 3
+    store i32 %ksize, i32* %4, align 4
+    %5 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 4
+    store i32 %vsize, i32* %5, align 4
+    %6 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 5
+    store i8* null, i8** %6, align 8
+    %7 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 6
+    store i8* null, i8** %7, align 8
+    %8 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 7
+    store i8* null, i8** %8, align 8
+    %9 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 8
+    store i32 (i8*)* %hash, i32 (i8*)** %9, align 8
+    %10 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 9
+    store i1 (i8*, i8*)* %equal, i1 (i8*, i8*)** %10, align 8
+    ret %type.map* %map
+}
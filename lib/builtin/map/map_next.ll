@@ -0,0 +1,34 @@
+source_filename = "lib/builtin/map/map_next.ll"
+
+%type.map = type { i32, i32, i32, i32, i32, i8*, i8*, i8*, i32 (i8*)*, i1 (i8*, i8*)* }
+
+; Finds the first slot in use from the given one onwards, or gives -1 when there is none
+define fastcc i32 @".next:map"(%type.map* %map, i32 %from) {
+entry:
+    %0 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 1
+    %capacity = load i32, i32* %0, align 4
+    %1 = getelementptr inbounds %type.map, %type.map* %map, i32 0, i32 7
+    %states = load i8*, i8** %1, align 8
+    br label %cond
+
+cond:
+    %i = phi i32 [ %from, %entry ], [ %next, %skip ]
+    %2 = icmp slt i32 %i, %capacity
+    br i1 %2, label %check, label %end
+
+check:
+    %3 = getelementptr inbounds i8, i8* %states, i32 %i
+    %4 = load i8, i8* %3, align 1
+    %5 = icmp eq i8 %4, 1
+    br i1 %5, label %found, label %skip
+
+found:
+    ret i32 %i
+
+skip:
+    %next = add i32 %i, 1
+    br label %cond
+
+end:
+    ret i32 -1
+}
@@ -2,7 +2,7 @@ source_filename = "lib/builtin/reference/free_ref_msg.ll"
 
 %type.string = type { i32, i32* }
 
-declare fastcc void @.println(%type.string %0)
+declare fastcc void @".println:string"(%type.string %0)
 declare fastcc %type.string @".add:string_string"(%type.string %0, %type.string %1)
 declare fastcc %type.string @".conv:int_string"(i32 %0)
 
@@ -18,7 +18,7 @@ entry:
 	%3 = getelementptr inbounds %type.string, %type.string* %1, i32 0, i32 1
 	store i32* %0, i32** %3, align 8
 	%4 = load %type.string, %type.string* %1, align 8
-	call void @.println(%type.string %4)
+	call void @".println:string"(%type.string %4)
 	ret void
 }
 
@@ -33,6 +33,6 @@ entry:
 	store i32* %2, i32** %5, align 8
 	%6 = load %type.string, %type.string* %3, align 8
 	%7 = call %type.string @".add:string_string"(%type.string %1, %type.string %6)
-	call void @.println(%type.string %7)
+	call void @".println:string"(%type.string %7)
 	ret void
 }
\ No newline at end of file
@@ -3,6 +3,9 @@ package main
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"sulfur/src/errors"
 	"sulfur/src/settings"
 	"sulfur/src/sulfurc"
 	"sulfur/src/utils"
@@ -14,14 +17,35 @@ func main() {
 	args := utils.NewQueue(os.Args[1:])
 
 	mode := args.AttemptNext("No mode given")
+	if mode == "explain" {
+		code := args.AttemptNext("No error code given")
+		if text, ok := errors.Explain(code); ok {
+			fmt.Print(text)
+		} else {
+			utils.Panic("Unknown error code \"" + code + "\"")
+		}
+		return
+	}
+
 	input := args.AttemptNext("No file given")
-	output := utils.FileName(input)
+	output := ""
 
 	for !args.Empty() {
 		name := *args.Consume()
 
 		if name[0] == '-' { // Is a flag
-			switch name[1:] {
+			flag, val, _ := strings.Cut(name[1:], "=")
+			if _, ok := settings.Tools[flag]; ok && val != "" {
+				settings.Tools[flag] = val
+				continue
+			}
+
+			if warning, ok := strings.CutPrefix(flag, "W"); ok {
+				setWarning(warning)
+				continue
+			}
+
+			switch flag {
 			case "trace":
 				settings.Stacktrace = true
 			case "debug":
@@ -34,17 +58,63 @@ func main() {
 				} else {
 					utils.Panic("No output file given")
 				}
+			case "target":
+				if val != "" {
+					settings.Target = val
+				} else if arg, ok := args.Next(); ok {
+					settings.Target = *arg
+				} else {
+					utils.Panic("No target given")
+				}
+			case "emit":
+				sulfurc.SetEmit(val)
+			case "runtime":
+				settings.Runtime = val
+			case "diagnostics":
+				switch val {
+				case settings.Text, settings.JSON, settings.SARIF:
+					settings.Diagnostics = val
+				default:
+					utils.Panic("Invalid diagnostics format \"" + val + "\"")
+				}
+			case "max-errors":
+				if max, err := strconv.Atoi(val); err == nil && max >= 0 {
+					settings.MaxErrors = max
+				} else {
+					utils.Panic("Invalid error limit \"" + val + "\"")
+				}
 			}
 		}
 	}
 
 	name := utils.FileName(input)
-
-	sulfurc.Clear()
+	if output == "" {
+		output = name + sulfurc.Emit.Extension()
+	}
 
 	sulfurc.SetMode(mode)
-	sulfurc.Compile(name, input, output)
-	fmt.Println("Compile time:", time.Since(start))
+	if sulfurc.Mode != sulfurc.Check {
+		sulfurc.Clear()
+	}
+
+	artifact := sulfurc.Compile(name, input, output)
+	if settings.Diagnostics == settings.Text {
+		fmt.Println("Compile time:", time.Since(start))
+	}
 
-	sulfurc.Execute(name, output)
+	sulfurc.Execute(artifact, output)
+}
+
+// Handles -Werror, -W<name> and -Wno-<name>
+func setWarning(warning string) {
+	if warning == "error" {
+		settings.WarningsAsErrors = true
+		return
+	}
+
+	name, disable := strings.CutPrefix(warning, "no-")
+	if _, ok := settings.Warnings[name]; !ok {
+		utils.Panic("Unknown warning \"" + name + "\"")
+	}
+	settings.Warnings[name] = !disable
 }
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sulfur/src/settings"
+	"testing"
+)
+
+// Runs every example that has a .out file next to it and compares what it prints
+func TestExamples(t *testing.T) {
+	for _, tool := range settings.Tools {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skip("missing " + tool)
+		}
+	}
+
+	dir := t.TempDir()
+	cli := filepath.Join(dir, "sulfur")
+	if out, err := exec.Command("go", "build", "-o", cli, ".").CombinedOutput(); err != nil {
+		t.Fatal(string(out))
+	}
+
+	runtime, _ := filepath.Abs("lib/builtin/linked.bc")
+	expected, _ := filepath.Glob("examples/*.out")
+	for _, path := range expected {
+		source, _ := filepath.Abs(strings.TrimSuffix(path, ".out") + ".su")
+		want, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Run(filepath.Base(source), func(t *testing.T) {
+			command := exec.Command(cli, "run", source, "-colorless", "-runtime="+runtime)
+			command.Dir = dir
+			out, err := command.CombinedOutput()
+			if err != nil {
+				t.Fatalf("%v\n%s", err, out)
+			}
+			if got := printed(string(out)); got != string(want) {
+				t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+			}
+		})
+	}
+}
+
+// Leaves out what the compiler and the runtime report about themselves
+func printed(out string) string {
+	var lines []string
+	for _, line := range strings.SplitAfter(out, "\n") {
+		if !strings.HasPrefix(line, "Compile time:") && !strings.HasPrefix(line, "Automatically freed") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "")
+}
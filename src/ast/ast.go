@@ -23,6 +23,7 @@ type (
 		TypeConvs   []builtins.TypeConvSignature   `json:"-"`
 		IncDecs     []builtins.IncDecSignature     `json:"-"`
 		Classes     []builtins.ClassSignature      `json:"-"`
+		Enums       []builtins.EnumSignature       `json:"-"`
 		Strings     []String                       `json:"-"`
 		FuncScope   *FuncScope                     `json:"-"`
 		Contents    Block
@@ -68,38 +69,98 @@ type (
 		Value string
 	}
 
+	// Type is the type of the items, not of the array itself
 	Array struct {
+		End   *location.Location `json:"-"`
 		Type  Identifier
 		Items *[]Expr
 	}
 
+	Index struct {
+		End   *location.Location `json:"-"`
+		Array Expr
+		Index Expr
+	}
+
+	// Like arrays, Type is the type of the items
+	List struct {
+		Pos   *location.Location `json:"-"`
+		End   *location.Location `json:"-"`
+		Type  Identifier
+		Items *[]Expr
+	}
+
+	Map struct {
+		Pos    *location.Location `json:"-"`
+		End    *location.Location `json:"-"`
+		Key    Identifier
+		Value  Identifier
+		Keys   *[]Expr
+		Values *[]Expr
+	}
+
+	// Passes the items of an array as the arguments of a variadic parameter, like f(...xs)
+	Spread struct {
+		Pos   *location.Location `json:"-"`
+		Value Expr
+	}
+
+	// The body is an expression evaluated in a function of its own, which reaches the variables it captures through ref boxes
+	Lambda struct {
+		Pos       *location.Location `json:"-"`
+		Params    *[]Param
+		Body      Expr
+		Scope     *Scope     `json:"-"`
+		FuncScope *FuncScope `json:"-"`
+		Captures  *[]Capture `json:"-"`
+	}
+
+	// Only returned from functions, which hand back every item at once
+	Tuple struct {
+		Pos   *location.Location `json:"-"`
+		End   *location.Location `json:"-"`
+		Items *[]Expr
+	}
+
 	Function struct {
 		Pos       *location.Location `json:"-"`
 		Name      Identifier
 		Params    []Param
-		Return    Identifier
+		Return    []Identifier
+		Throws    Identifier `json:",omitempty"`
 		FuncScope *FuncScope `json:"-"`
 		Body      Block
 	}
 
 	Class struct {
+		Pos         *location.Location `json:"-"`
+		Fields      []Field
+		Methods     []Method
+		New         NewDel
+		Del         NewDel
+		Name        Identifier
+		Extends     Identifier
+		Operations  []Operation
+		Conversions []To
+	}
+
+	// Values holds the initializer of each element, or NoExpr for those without one
+	Enum struct {
 		Pos    *location.Location `json:"-"`
-		Fields []Field
 		Name   Identifier
+		From   Identifier `json:",omitempty"`
+		Elems  []Identifier
+		Values []Expr
+		Error  bool `json:",omitempty"`
 	}
 
-	Enum struct {
-		Pos   *location.Location `json:"-"`
-		Name  Identifier
-		From  Identifier `json:",omitempty"`
-		Elems []Identifier
-	}
-
+	// The type of a variadic parameter is already the array its arguments are collected into
 	Param struct {
 		Pos        *location.Location `json:"-"`
 		Type       Identifier
 		Name       Identifier
 		Referenced bool
+		Variadic   bool
 	}
 
 	Field struct {
@@ -112,29 +173,34 @@ type (
 		Visibility lexer.Token
 		Name       Identifier
 		Params     []Param
-		Return     Identifier
+		Return     []Identifier
+		FuncScope  *FuncScope `json:"-"`
 		Body       Block
 	}
 
 	NewDel struct {
+		Pos        *location.Location `json:"-"`
 		Visibility lexer.Token
 		Which      lexer.TokenType
 		Params     []Param
+		FuncScope  *FuncScope `json:"-"`
 		Body       Block
 	}
 
 	To struct {
-		Pos  *location.Location `json:"-"`
-		Type Identifier
-		Body Block
+		Pos       *location.Location `json:"-"`
+		Type      Identifier
+		FuncScope *FuncScope `json:"-"`
+		Body      Block
 	}
 
 	Operation struct {
-		Pos    *location.Location `json:"-"`
-		Op     *lexer.Token
-		Params []Param
-		Return []Identifier
-		Body   Block
+		Pos       *location.Location `json:"-"`
+		Op        lexer.Token
+		Params    []Param
+		Return    []Identifier
+		FuncScope *FuncScope `json:"-"`
+		Body      Block
 	}
 
 	Access struct {
@@ -144,8 +210,15 @@ type (
 		Child  Identifier
 	}
 
+	MethodCall struct {
+		End    *location.Location `json:"-"`
+		Method Access
+		Params *[]Expr
+	}
+
 	New struct {
 		Pos    *location.Location `json:"-"`
+		End    *location.Location `json:"-"`
 		Class  Identifier
 		Params *[]Expr
 	}
@@ -166,11 +239,6 @@ type (
 		Variable Identifier
 	}
 
-	Pipe struct {
-		Left  FuncCall
-		Right FuncCall
-	}
-
 	Comparison struct {
 		Left  Expr
 		Right Expr
@@ -190,23 +258,45 @@ type (
 		Value      Expr
 	}
 
+	// Declares a variable for every value returned by a function, like let q, r = divmod(x, y)
+	Unpack struct {
+		Pos    *location.Location `json:"-"`
+		Prefix lexer.TokenType
+		Names  []Identifier
+		Value  Expr
+	}
+
 	Assignment struct {
 		Name  Identifier
 		Value Expr
 		Op    lexer.Token
 	}
 
+	FieldAssignment struct {
+		Field Access
+		Value Expr
+		Op    lexer.Token
+	}
+
+	IndexAssignment struct {
+		Index Index
+		Value Expr
+		Op    lexer.Token
+	}
+
 	IncDec struct {
 		Name Identifier
 		Op   lexer.Token
 	}
 
 	FuncCall struct {
+		End    *location.Location `json:"-"`
 		Func   Identifier
 		Params *[]Expr
 	}
 
 	TypeConv struct {
+		End   *location.Location `json:"-"`
 		Type  Identifier
 		Value Expr
 	}
@@ -218,6 +308,48 @@ type (
 		Else Block
 	}
 
+	Match struct {
+		Pos   *location.Location `json:"-"`
+		Value Expr
+		Arms  *[]Arm
+	}
+
+	// Arms without any patterns match everything the others do not
+	Arm struct {
+		Pos         *location.Location `json:"-"`
+		Patterns    *[]Expr
+		Body        Block
+		Fallthrough bool
+	}
+
+	// Ranges include both of their ends
+	Range struct {
+		Start Expr
+		End   Expr
+	}
+
+	Fallthrough struct {
+		Pos *location.Location `json:"-"`
+	}
+
+	Defer struct {
+		Pos   *location.Location `json:"-"`
+		Value Expr
+	}
+
+	Throw struct {
+		Pos   *location.Location `json:"-"`
+		Value Expr
+	}
+
+	// A try either catches the error with its arms, gives Catch instead of the value, or throws the error on when neither is given
+	Try struct {
+		Pos   *location.Location `json:"-"`
+		Value Expr
+		Catch Expr
+		Arms  *[]Arm
+	}
+
 	ForLoop struct {
 		Pos  *location.Location `json:"-"`
 		Init Expr
@@ -226,6 +358,13 @@ type (
 		Body Block
 	}
 
+	ForEach struct {
+		Pos      *location.Location `json:"-"`
+		Item     Identifier
+		Iterable Expr
+		Body     Block
+	}
+
 	WhileLoop struct {
 		Pos  *location.Location `json:"-"`
 		Cond Expr
@@ -266,37 +405,61 @@ func (x UnsignedInteger) Loc() *location.Location { return x.Pos }
 func (x Float) Loc() *location.Location           { return x.Pos }
 func (x Boolean) Loc() *location.Location         { return x.Pos }
 func (x String) Loc() *location.Location          { return x.Pos }
-func (x Array) Loc() *location.Location           { return x.Type.Loc() }
+func (x Array) Loc() *location.Location           { return location.Span(x.Type.Loc(), x.End) }
+func (x Index) Loc() *location.Location           { return location.Span(x.Array.Loc(), x.End) }
+func (x List) Loc() *location.Location            { return location.Span(x.Pos, x.End) }
+func (x Map) Loc() *location.Location             { return location.Span(x.Pos, x.End) }
+func (x Spread) Loc() *location.Location          { return location.Span(x.Pos, x.Value.Loc()) }
+func (x Lambda) Loc() *location.Location          { return location.Span(x.Pos, x.Body.Loc()) }
+func (x Tuple) Loc() *location.Location           { return location.Span(x.Pos, x.End) }
 func (x Function) Loc() *location.Location        { return x.Pos }
 func (x Class) Loc() *location.Location           { return x.Pos }
 func (x Enum) Loc() *location.Location            { return x.Pos }
 func (x Param) Loc() *location.Location           { return x.Pos }
 func (x Field) Loc() *location.Location           { return x.Visibility.Location }
 func (x Method) Loc() *location.Location          { return x.Visibility.Location }
-func (x NewDel) Loc() *location.Location          { return x.Visibility.Location }
+func (x NewDel) Loc() *location.Location          { return x.Pos }
 func (x To) Loc() *location.Location              { return x.Pos }
 func (x Operation) Loc() *location.Location       { return x.Pos }
-func (x Access) Loc() *location.Location          { return x.Pos }
-func (x New) Loc() *location.Location             { return x.Pos }
-func (x BinaryOp) Loc() *location.Location        { return x.Left.Loc() }
-func (x UnaryOp) Loc() *location.Location         { return x.Value.Loc() }
-func (x Reference) Loc() *location.Location       { return x.Pos }
-func (x Pipe) Loc() *location.Location            { return x.Left.Func.Loc() }
-func (x Comparison) Loc() *location.Location      { return x.Left.Loc() }
+func (x Access) Loc() *location.Location          { return location.Span(x.Pos, x.Child.Loc()) }
+func (x MethodCall) Loc() *location.Location      { return location.Span(x.Method.Loc(), x.End) }
+func (x New) Loc() *location.Location             { return location.Span(x.Pos, x.End) }
+func (x BinaryOp) Loc() *location.Location        { return location.Span(x.Left.Loc(), x.Right.Loc()) }
+func (x UnaryOp) Loc() *location.Location         { return location.Span(x.Op.Location, x.Value.Loc()) }
+func (x Reference) Loc() *location.Location       { return location.Span(x.Pos, x.Variable.Loc()) }
+func (x Comparison) Loc() *location.Location      { return location.Span(x.Left.Loc(), x.Right.Loc()) }
 func (x Declaration) Loc() *location.Location     { return x.Pos }
+func (x Unpack) Loc() *location.Location          { return x.Pos }
 func (x Assignment) Loc() *location.Location      { return x.Name.Loc() }
-func (x IncDec) Loc() *location.Location          { return x.Name.Loc() }
-func (x FuncCall) Loc() *location.Location        { return x.Func.Loc() }
-func (x TypeConv) Loc() *location.Location        { return x.Type.Loc() }
+func (x FieldAssignment) Loc() *location.Location { return x.Field.Loc() }
+func (x IndexAssignment) Loc() *location.Location { return x.Index.Loc() }
+func (x IncDec) Loc() *location.Location          { return location.Span(x.Name.Loc(), x.Op.Location) }
+func (x FuncCall) Loc() *location.Location        { return location.Span(x.Func.Loc(), x.End) }
+func (x TypeConv) Loc() *location.Location        { return location.Span(x.Type.Loc(), x.End) }
 func (x IfStatement) Loc() *location.Location     { return x.Pos }
+func (x Match) Loc() *location.Location           { return x.Pos }
+func (x Range) Loc() *location.Location           { return location.Span(x.Start.Loc(), x.End.Loc()) }
+func (x Fallthrough) Loc() *location.Location     { return x.Pos }
+func (x Defer) Loc() *location.Location           { return x.Pos }
+func (x Throw) Loc() *location.Location           { return x.Pos }
+func (x Try) Loc() *location.Location             { return location.Span(x.Pos, x.Value.Loc()) }
 func (x ForLoop) Loc() *location.Location         { return x.Pos }
+func (x ForEach) Loc() *location.Location         { return x.Pos }
 func (x WhileLoop) Loc() *location.Location       { return x.Pos }
 func (x DoWhileLoop) Loc() *location.Location     { return x.Pos }
 func (x Loop) Loc() *location.Location            { return x.Pos }
-func (x Return) Loc() *location.Location          { return x.Pos }
+func (x Return) Loc() *location.Location          { return location.Span(x.Pos, x.Value.Loc()) }
 func (x Break) Loc() *location.Location           { return x.Pos }
 func (x Continue) Loc() *location.Location        { return x.Pos }
 
+// Constructors and destructors are called new and del
+func (x NewDel) Name() string {
+	if x.Which == lexer.Delete {
+		return "del"
+	}
+	return "new"
+}
+
 func Valid(expr Expr) bool {
 	if _, ok := expr.(*NoExpr); ok {
 		return false
@@ -310,3 +473,26 @@ func Empty(expr Expr) bool {
 	}
 	return false
 }
+
+// The type of a literal, or an invalid type if the expression is not one
+func LiteralType(expr Expr) typing.Type {
+	switch x := expr.(type) {
+	case Integer:
+		return typing.Integer
+	case UnsignedInteger:
+		return typing.Unsigned
+	case Float:
+		return typing.Float
+	case Boolean:
+		return typing.Boolean
+	case String:
+		return typing.String
+	case UnaryOp:
+		if x.Op.Type == lexer.Subtraction {
+			if typ := LiteralType(x.Value); typ == typing.Integer || typ == typing.Float {
+				return typ
+			}
+		}
+	}
+	return typing.Invalid
+}
@@ -9,6 +9,7 @@ import (
 type FuncScope struct {
 	Parent *FuncScope
 	Return typing.Type
+	Throws typing.Type
 	Decls  map[*Variable]*ir.InstAlloca
 	Counts map[string]int
 }
@@ -17,6 +18,7 @@ func NewFuncScope(parent *FuncScope, ret typing.Type) *FuncScope {
 	return &FuncScope{
 		parent,
 		ret,
+		"",
 		make(map[*Variable]*ir.InstAlloca),
 		make(map[string]int),
 	}
@@ -1,9 +1,11 @@
 package ast
 
 import (
+	"sort"
 	. "sulfur/src/errors"
 	"sulfur/src/location"
 	"sulfur/src/typing"
+	"sulfur/src/utils"
 
 	"github.com/llir/llvm/ir"
 	"github.com/llir/llvm/ir/value"
@@ -19,16 +21,47 @@ type Scope struct {
 	Loop       bool
 	Seperate   bool
 	Strings    map[value.Value]typing.Type
+	Objects    []Object
+	Owners     []*Variable
+	Defers     []Expr
+}
+
+// An instance of a class held by the scope it was created or returned in, which lets go of it when left
+type Object struct {
+	Value value.Value
+	Type  typing.Type
 }
 
 func (s *Scope) Lookup(name string, loc *location.Location) *Variable {
-	if vari, ok := s.Vars[name]; ok {
+	if vari, ok := s.Find(name); ok {
 		return vari
 	}
+
+	Errors.Error(UndefinedVariable, "'"+name+"' is not defined"+utils.Suggest(name, s.Names()), loc)
+	return &Variable{Name: name, Type: typing.Invalid}
+}
+
+func (s *Scope) Find(name string) (*Variable, bool) {
+	if vari, ok := s.Vars[name]; ok {
+		return vari, true
+	}
 	if s.Parent == nil || s.Seperate {
-		Errors.Error("'"+name+"' is not defined", loc)
+		return nil, false
 	}
-	return s.Parent.Lookup(name, loc)
+	return s.Parent.Find(name)
+}
+
+// Names of the innermost scope come first, so that they are preferred when suggesting one
+func (s *Scope) Names() []string {
+	names := []string{}
+	for name := range s.Vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if s.Parent == nil || s.Seperate {
+		return names
+	}
+	return append(names, s.Parent.Names()...)
 }
 
 func (s *Scope) RefLookup(name string, loc *location.Location) value.Value {
@@ -36,7 +69,7 @@ func (s *Scope) RefLookup(name string, loc *location.Location) value.Value {
 		return ref
 	}
 	if s.Parent == nil || s.Seperate {
-		Errors.Error("'"+name+"' is not defined", loc)
+		Errors.Fatal(Internal, "'"+name+"' is not defined", loc)
 	}
 	return s.Parent.RefLookup(name, loc)
 }
@@ -56,7 +89,7 @@ func (s *Scope) FindEntrance(loc *location.Location) *ir.Block {
 		return s.Entrance
 	}
 	if s.Parent == nil {
-		Errors.Error("Something went wrong finding an entrance to a block", loc)
+		Errors.Fatal(Internal, "Something went wrong finding an entrance to a block", loc)
 	}
 	return s.Parent.FindEntrance(loc)
 }
@@ -66,7 +99,7 @@ func (s *Scope) FindExit(loc *location.Location) *ir.Block {
 		return s.Exit
 	}
 	if s.Parent == nil {
-		Errors.Error("Something went wrong finding an exit to a block", loc)
+		Errors.Fatal(Internal, "Something went wrong finding an exit to a block", loc)
 	}
 	return s.Parent.FindExit(loc)
 }
@@ -92,5 +125,8 @@ func NewScope() *Scope {
 		false,
 		false,
 		make(map[value.Value]typing.Type),
+		[]Object{},
+		[]*Variable{},
+		[]Expr{},
 	}
 }
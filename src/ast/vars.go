@@ -2,6 +2,7 @@ package ast
 
 import (
 	"fmt"
+	"sulfur/src/location"
 	"sulfur/src/typing"
 
 	"github.com/llir/llvm/ir/value"
@@ -16,12 +17,15 @@ const (
 )
 
 type Variable struct {
+	Pos        *location.Location
 	Name       string
 	Id         int
 	Referenced bool
 	References bool
+	Captured   bool
 	Type       typing.Type
 	Status     VariableType
+	Uses       int
 	Value      value.Value
 }
 
@@ -33,16 +37,25 @@ func (v *Variable) LLName() string {
 	}
 }
 
-func NewVariable(fscope *FuncScope, name string, refs bool, typ typing.Type, status VariableType) *Variable {
+func NewVariable(fscope *FuncScope, pos *location.Location, name string, refs bool, typ typing.Type, status VariableType) *Variable {
 	vari := &Variable{
+		pos,
 		name,
 		fscope.Counts[name],
 		false,
 		refs,
+		false,
 		typ,
 		status,
+		0,
 		nil,
 	}
 	fscope.Counts[name]++
 	return vari
 }
+
+// A variable captured by a lambda, and the variable standing in for it inside of the lambda
+type Capture struct {
+	Outer *Variable
+	Inner *Variable
+}
@@ -6,8 +6,8 @@ import (
 )
 
 var Funcs = []FuncSignature{
-	QuickFunc("print", typing.Void, typing.String),
-	QuickFunc("println", typing.Void, typing.String),
+	QuickModFunc("", "print", typing.Void, QuickVariadicParam(typing.String)),
+	QuickModFunc("", "println", typing.Void, QuickVariadicParam(typing.String)),
 }
 
 var BinaryOps = []BinaryOpSignature{
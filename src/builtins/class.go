@@ -0,0 +1,110 @@
+package builtins
+
+// Finds a field, including inherited ones, along with its position among all of the class' fields
+func (class *ClassSignature) Field(name string) (int, *FieldSignature, bool) {
+	offset := 0
+	if class.Parent != nil {
+		if i, field, ok := class.Parent.Field(name); ok {
+			return i, field, true
+		}
+		offset = len(class.Parent.AllFields())
+	}
+
+	for i := range class.Fields {
+		if class.Fields[i].Name == name {
+			return offset + i, &class.Fields[i], true
+		}
+	}
+	return -1, nil, false
+}
+
+// Finds a method, including inherited ones, though constructors and destructors are never inherited
+func (class *ClassSignature) Method(name string) (*MethodSignature, bool) {
+	if method, ok := class.Own(name); ok {
+		return method, true
+	}
+	if class.Parent != nil && name != "new" && name != "del" {
+		return class.Parent.Method(name)
+	}
+	return nil, false
+}
+
+func (class *ClassSignature) Own(name string) (*MethodSignature, bool) {
+	for i := range class.Methods {
+		if class.Methods[i].Name == name {
+			return &class.Methods[i], true
+		}
+	}
+	return nil, false
+}
+
+// A class without a constructor is created with the constructor of its parent
+func (class *ClassSignature) Constructor() (*ClassSignature, *MethodSignature, bool) {
+	if ctor, ok := class.Own("new"); ok {
+		return class, ctor, true
+	}
+	if class.Parent != nil {
+		return class.Parent.Constructor()
+	}
+	return nil, nil, false
+}
+
+// Finds the class that defines a field or method
+func (class *ClassSignature) Owner(name string) *ClassSignature {
+	if class.Parent != nil {
+		if _, _, ok := class.Parent.Field(name); ok {
+			return class.Parent.Owner(name)
+		}
+	}
+	for _, field := range class.Fields {
+		if field.Name == name {
+			return class
+		}
+	}
+	if _, ok := class.Own(name); ok {
+		return class
+	}
+	if class.Parent != nil {
+		return class.Parent.Owner(name)
+	}
+	return nil
+}
+
+func (class *ClassSignature) AllFields() []FieldSignature {
+	fields := []FieldSignature{}
+	if class.Parent != nil {
+		fields = class.Parent.AllFields()
+	}
+	return append(fields, class.Fields...)
+}
+
+// The names of the methods in the class' vtable, where the parent's methods come first
+func (class *ClassSignature) Slots() []string {
+	slots := []string{}
+	if class.Parent != nil {
+		slots = class.Parent.Slots()
+	}
+
+	for _, method := range class.Methods {
+		if method.Name == "new" || method.Name == "del" {
+			continue
+		}
+		if class.Parent != nil {
+			if _, ok := class.Parent.Method(method.Name); ok {
+				continue
+			}
+		}
+		slots = append(slots, method.Name)
+	}
+	return slots
+}
+
+// Whether the class is the other class, or extends it
+func (class *ClassSignature) Is(other *ClassSignature) bool {
+	for parent := class; parent != nil; parent = parent.Parent {
+		if parent == other {
+			return true
+		}
+	}
+	return false
+}
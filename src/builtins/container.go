@@ -0,0 +1,34 @@
+package builtins
+
+import (
+	"sulfur/src/lexer"
+	"sulfur/src/typing"
+)
+
+// The methods every list has, where the items are of the given type
+func ListMethods(item typing.Type) []MethodSignature {
+	return []MethodSignature{
+		QuickMethod(lexer.Public, "push", typing.Void, QuickParam(item)),
+		QuickMethod(lexer.Public, "pop", item),
+		QuickMethod(lexer.Public, "insert", typing.Void, QuickParam(typing.Integer), QuickParam(item)),
+		QuickMethod(lexer.Public, "remove", item, QuickParam(typing.Integer)),
+		QuickMethod(lexer.Public, "clear", typing.Void),
+	}
+}
+
+// The methods every map has, where the keys and values are of the given types
+func MapMethods(key, val typing.Type) []MethodSignature {
+	return []MethodSignature{
+		QuickMethod(lexer.Public, "has", typing.Boolean, QuickParam(key)),
+		QuickMethod(lexer.Public, "delete", typing.Void, QuickParam(key)),
+	}
+}
+
+func FindMethod(methods []MethodSignature, name string) (MethodSignature, bool) {
+	for _, method := range methods {
+		if method.Name == name {
+			return method, true
+		}
+	}
+	return MethodSignature{}, false
+}
@@ -9,6 +9,7 @@ func QuickModFunc(mod string, name string, ret typing.Type, params ...ParamSigna
 	return FuncSignature{
 		name,
 		ret,
+		"",
 		params,
 		mod,
 		nil,
@@ -20,16 +21,32 @@ func QuickModParam(typ typing.Type, ref bool) ParamSignature {
 	return ParamSignature{
 		typ,
 		ref,
+		false,
 		nil,
 	}
 }
 
-func QuickModClass(mod string, name string, fields []FieldSignature) ClassSignature {
+func QuickModClass(mod string, name string, extends string, fields []FieldSignature, methods []MethodSignature) ClassSignature {
 	return ClassSignature{
 		name,
+		extends,
+		nil,
 		fields,
+		methods,
 		mod,
 		nil,
+		nil,
+		nil,
+	}
+}
+
+func QuickModEnum(mod string, name string, typ typing.Type, elems []string) EnumSignature {
+	return EnumSignature{
+		name,
+		typ,
+		elems,
+		mod,
+		false,
 	}
 }
 
@@ -43,6 +60,7 @@ func QuickModBinOp(mod string, left, right typing.Type, op lexer.TokenType) Bina
 		nil,
 		0,
 		false,
+		false,
 	}
 }
 
@@ -18,8 +18,21 @@ func QuickParam(typ typing.Type) ParamSignature {
 	return QuickModParam(typ, false)
 }
 
-func QuickClass(name string, fields []FieldSignature) ClassSignature {
-	return QuickModClass("", name, fields)
+func QuickVariadicParam(item typing.Type) ParamSignature {
+	return ParamSignature{
+		typing.Array(item),
+		false,
+		true,
+		nil,
+	}
+}
+
+func QuickClass(name string, extends string, fields []FieldSignature, methods []MethodSignature) ClassSignature {
+	return QuickModClass("", name, extends, fields, methods)
+}
+
+func QuickEnum(name string, typ typing.Type, elems []string) EnumSignature {
+	return QuickModEnum("", name, typ, elems)
 }
 
 func QuickField(vis lexer.TokenType, typ typing.Type, name string) FieldSignature {
@@ -30,6 +43,17 @@ func QuickField(vis lexer.TokenType, typ typing.Type, name string) FieldSignatur
 	}
 }
 
+func QuickMethod(vis lexer.TokenType, name string, ret typing.Type, params ...ParamSignature) MethodSignature {
+	return MethodSignature{
+		vis,
+		name,
+		ret,
+		params,
+		nil,
+		0,
+	}
+}
+
 func QuickBinOp(left, right typing.Type, op lexer.TokenType) BinaryOpSignature {
 	return QuickModBinOp("", left, right, op)
 }
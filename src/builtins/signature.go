@@ -9,26 +9,46 @@ import (
 )
 
 type (
+	// Functions that can throw name the error type they throw
 	FuncSignature struct {
 		Name   string
 		Return typing.Type
+		Throws typing.Type
 		Params []ParamSignature
 		Module string
 		Ir     *ir.Func
 		Uses   int
 	}
 
+	// A variadic parameter is always the last one, and its type is an array of what it takes
 	ParamSignature struct {
 		Type       typing.Type
 		Referenced bool
+		Variadic   bool
 		Ir         *ir.Param
 	}
 
+	// Fields only holds the class' own fields, the inherited ones are found through the parent
 	ClassSignature struct {
+		Name    string
+		Extends string
+		Parent  *ClassSignature
+		Fields  []FieldSignature
+		Methods []MethodSignature
+		Module  string
+		Ir      types.Type
+		Vtable  *ir.Global
+		Free    *ir.Func
+	}
+
+	// Each value of an enum is stored as its position, and Type is what the values convert to and from.
+	// Error types are enums whose values can be thrown, but not converted
+	EnumSignature struct {
 		Name   string
-		Fields []FieldSignature
+		Type   typing.Type
+		Elems  []string
 		Module string
-		Ir     types.Type
+		Error  bool
 	}
 
 	FieldSignature struct {
@@ -37,6 +57,16 @@ type (
 		Name       string
 	}
 
+	// Constructors and destructors are stored as methods named new and del
+	MethodSignature struct {
+		Visibility lexer.TokenType
+		Name       string
+		Return     typing.Type
+		Params     []ParamSignature
+		Ir         *ir.Func
+		Uses       int
+	}
+
 	BinaryOpSignature struct {
 		Left    typing.Type
 		Right   typing.Type
@@ -46,6 +76,7 @@ type (
 		Ir      *ir.Func
 		Uses    int
 		Complex bool
+		Swapped bool // Implemented by calling the operation with its operands the other way around
 	}
 
 	UnaryOpSignature struct {
@@ -0,0 +1,96 @@
+package checker
+
+import (
+	"sulfur/src/ast"
+	. "sulfur/src/errors"
+	"sulfur/src/lexer"
+	"sulfur/src/typing"
+)
+
+func (c *checker) inferArray(x ast.Array) typing.Type {
+	known := c.known(x.Type)
+	typ := typing.Type(x.Type.Name)
+	for _, item := range *x.Items {
+		val := c.inferExpr(item)
+		if !known || !c.valued(val, item) || val == typ {
+			continue
+		}
+
+		if _, ok := c.AutoSingleInfer(val, typ, item); !ok {
+			Errors.Error(MismatchedTypes, "Expected "+typ.String()+", but got "+val.String()+" instead", item.Loc())
+		}
+	}
+
+	if !known {
+		return c.typ(x, typing.Invalid)
+	}
+	return c.typ(x, typing.Array(typ))
+}
+
+func (c *checker) inferIndex(x ast.Index) typing.Type {
+	arr := c.inferExpr(x.Array)
+	key, val, isMap := arr.Entry()
+	if !isMap {
+		key = typing.Integer
+	}
+
+	idx := c.inferExpr(x.Index)
+	if c.valued(idx, x.Index) && idx != key {
+		if _, ok := c.AutoSingleInfer(idx, key, x.Index); !ok {
+			Errors.Error(MismatchedTypes, "Expected "+key.String()+", but got "+idx.String()+" instead", x.Index.Loc())
+		}
+	}
+	if !c.valued(arr, x.Array) {
+		return c.typ(x, typing.Invalid)
+	}
+	if isMap {
+		return c.typ(x, val)
+	}
+
+	item, ok := arr.Item()
+	if !ok {
+		item, ok = arr.ListItem()
+	}
+	if !ok {
+		Errors.Error(NotAnArray, "Cannot index "+arr.String()+", as it is not an array, list or map", x.Array.Loc())
+		return c.typ(x, typing.Invalid)
+	}
+	return c.typ(x, item)
+}
+
+// Items can be changed through any array, list or map, as they are passed by reference like instances
+func (c *checker) inferIndexAssignment(x ast.IndexAssignment) {
+	item := c.inferIndex(x.Index)
+	val := c.inferExpr(x.Value)
+	if item == typing.Invalid || !c.valued(val, x.Value) {
+		return
+	}
+
+	if !lexer.Empty(x.Op) && item != val && c.compound(x.Op, item, val, x.Value) {
+		return
+	}
+
+	if item != val {
+		conv, ok := c.AutoSingleInfer(val, item, x.Value)
+		if ok {
+			val, _ = AutoSwitch(val, item, conv)
+		} else {
+			Errors.Error(MismatchedTypes, "Expected "+item.String()+", but got "+val.String()+" instead", x.Value.Loc())
+			return
+		}
+	}
+
+	if lexer.Empty(x.Op) || c.compound(x.Op, item, val, x.Value) {
+		return
+	}
+
+	Errors.Error(UndefinedOperation, "No operation "+x.Op.Value+" exists for "+item.String()+" and "+val.String(), x.Op.Location)
+}
+
+func (c *checker) inferLength(x ast.Access, kind string) typing.Type {
+	if x.Child.Name != "length" {
+		Errors.Error(UndefinedMember, kind+" have no member "+x.Child.Name+", only a length", x.Child.Loc())
+		return c.typ(x, typing.Invalid)
+	}
+	return c.typ(x, typing.Integer)
+}
@@ -3,7 +3,6 @@ package checker
 import (
 	"sulfur/src/ast"
 	"sulfur/src/builtins"
-	. "sulfur/src/errors"
 	"sulfur/src/typing"
 )
 
@@ -22,13 +21,6 @@ var order []typing.Type = []typing.Type{
 const boolAcceptable = 7
 
 func (c *checker) AutoInfer(a, b typing.Type, srcA, srcB ast.Expr) (builtins.TypeConvSignature, bool) {
-	if a == typing.Void {
-		Errors.Error("Cannot operator on values with no type", srcA.Loc())
-	}
-	if b == typing.Void {
-		Errors.Error("Cannot operator on values with no type", srcB.Loc())
-	}
-
 	idxA, idxB := -1, -1
 	foundA, foundB := false, false
 	for i, typ := range order {
@@ -46,11 +38,8 @@ func (c *checker) AutoInfer(a, b typing.Type, srcA, srcB ast.Expr) (builtins.Typ
 		}
 	}
 
-	if !foundA {
-		Errors.Error("Cannot yet convert classes", srcA.Loc())
-	}
-	if !foundB {
-		Errors.Error("Cannot yet convert classes", srcB.Loc())
+	if !foundA || !foundB {
+		return builtins.TypeConvSignature{}, false
 	}
 
 	var from, to typing.Type
@@ -70,20 +59,14 @@ func (c *checker) AutoInfer(a, b typing.Type, srcA, srcB ast.Expr) (builtins.Typ
 		return builtins.TypeConvSignature{}, false
 	}
 
-	for i, conv := range builtins.TypeConvs {
-		if conv.From == from && conv.To == to {
-			c.AutoConvs[exitSrc] = conv
-			c.Types[exitSrc] = from
-			conv.Uses++
-			c.program.TypeConvs[i] = conv
-
-			return conv, true
-		}
-	}
-	return builtins.TypeConvSignature{}, false
+	return c.autoConv(from, to, exitSrc)
 }
 
 func (c *checker) AutoSingleInfer(have, want typing.Type, src ast.Expr) (builtins.TypeConvSignature, bool) {
+	if conv, ok := c.AutoUpcast(have, want, src); ok {
+		return conv, true
+	}
+
 	idxHave, idxWant := -1, -1
 	foundHave, foundWant := false, false
 	for i, typ := range order {
@@ -101,19 +84,14 @@ func (c *checker) AutoSingleInfer(have, want typing.Type, src ast.Expr) (builtin
 		}
 	}
 
-	if idxWant > idxHave {
-		for i, conv := range builtins.TypeConvs {
-			if conv.From == have && conv.To == want {
-				c.AutoConvs[src] = conv
-				c.Types[src] = have
-				conv.Uses++
-				c.program.TypeConvs[i] = conv
-
-				return conv, true
-			}
-		}
+	// Instances with a conversion to string can be used wherever strings are, such as when printing
+	if _, ok := c.classOf(have); ok && want == typing.String {
+		return c.autoConv(have, want, src)
 	}
 
+	if foundHave && idxWant > idxHave {
+		return c.autoConv(have, want, src)
+	}
 	return builtins.TypeConvSignature{}, false
 }
 
@@ -126,3 +104,32 @@ func AutoSwitch(srcA, srcB typing.Type, conv builtins.TypeConvSignature) (typing
 		return srcA, srcB
 	}
 }
+
+// Instances are automatically converted to the classes they extend
+func (c *checker) AutoUpcast(have, want typing.Type, src ast.Expr) (builtins.TypeConvSignature, bool) {
+	from, ok := c.classOf(have)
+	if !ok {
+		return builtins.TypeConvSignature{}, false
+	}
+	to, ok := c.classOf(want)
+	if !ok || !from.Is(to) {
+		return builtins.TypeConvSignature{}, false
+	}
+
+	return c.autoConv(have, want, src)
+}
+
+// Marks the source as implicitly converted, if such a conversion exists
+func (c *checker) autoConv(from, to typing.Type, src ast.Expr) (builtins.TypeConvSignature, bool) {
+	for i, conv := range c.program.TypeConvs {
+		if conv.From == from && conv.To == to {
+			c.AutoConvs[src] = conv
+			c.Types[src] = from
+			conv.Uses++
+			c.program.TypeConvs[i] = conv
+
+			return conv, true
+		}
+	}
+	return builtins.TypeConvSignature{}, false
+}
@@ -3,14 +3,21 @@ package checker
 import (
 	"sulfur/src/ast"
 	"sulfur/src/builtins"
+	. "sulfur/src/errors"
+	"sulfur/src/location"
 	"sulfur/src/typing"
 	"sulfur/src/utils"
 )
 
 type checker struct {
-	program *ast.Program
-	top     *ast.Scope
-	topfun  *ast.FuncScope
+	program   *ast.Program
+	top       *ast.Scope
+	topfun    *ast.FuncScope
+	funcs     []ast.Function
+	lambdas   []ast.Lambda
+	class     *builtins.ClassSignature
+	operators map[string]*location.Location
+	trying    bool // Whether the call being checked is tried
 	*VariableProperties
 }
 
@@ -19,6 +26,64 @@ func (c *checker) typ(x ast.Expr, typ typing.Type) typing.Type {
 	return typ
 }
 
+func (c *checker) valued(typ typing.Type, src ast.Expr) bool {
+	if typ == typing.Void {
+		Errors.Error(NoType, "Cannot operate on values with no type", src.Loc())
+		return false
+	}
+	return typ != typing.Invalid
+}
+
+func (c *checker) known(typ ast.Identifier) bool {
+	if item, ok := typing.Type(typ.Name).Item(); ok {
+		return c.known(ast.Identifier{Pos: typ.Pos, Name: string(item)})
+	}
+	if item, ok := typing.Type(typ.Name).ListItem(); ok {
+		return c.known(ast.Identifier{Pos: typ.Pos, Name: string(item)})
+	}
+	if key, val, ok := typing.Type(typ.Name).Entry(); ok {
+		known := c.known(ast.Identifier{Pos: typ.Pos, Name: string(key)})
+		known = c.known(ast.Identifier{Pos: typ.Pos, Name: string(val)}) && known
+		if known && !c.hashable(key) {
+			Errors.Error(InvalidKey, "Cannot use "+key.String()+" as the key of a map, as only int, uint, float, bool, string and enums can be hashed", typ.Loc())
+			return false
+		}
+		return known
+	}
+	if params, ret, ok := typing.Type(typ.Name).Signature(); ok {
+		known := true
+		for _, param := range params {
+			known = c.known(ast.Identifier{Pos: typ.Pos, Name: string(param)}) && known
+		}
+		rets := []typing.Type{ret}
+		if elems, ok := ret.Elems(); ok {
+			rets = elems
+		}
+		for _, ret := range rets {
+			if ret != typing.Void {
+				known = c.known(ast.Identifier{Pos: typ.Pos, Name: string(ret)}) && known
+			}
+		}
+		return known
+	}
+
+	names := utils.Apply(typing.Primitives, func(prim typing.Type) string {
+		return string(prim)
+	})
+	for _, class := range c.program.Classes {
+		names = append(names, class.Name)
+	}
+	for _, enum := range c.program.Enums {
+		names = append(names, enum.Name)
+	}
+
+	if utils.Contains(names, typ.Name) {
+		return true
+	}
+	Errors.Error(UndefinedType, "The type "+typ.Name+" is undefined"+utils.Suggest(typ.Name, names), typ.Loc())
+	return false
+}
+
 func TypeCheck(program *ast.Program) *VariableProperties {
 	program.Functions = append(program.Functions, builtins.Funcs...)
 	program.BinaryOps = append(program.BinaryOps, builtins.BinaryOps...)
@@ -31,6 +96,11 @@ func TypeCheck(program *ast.Program) *VariableProperties {
 		program,
 		program.Contents.Scope,
 		program.FuncScope,
+		[]ast.Function{},
+		[]ast.Lambda{},
+		nil,
+		make(map[string]*location.Location),
+		false,
 		&VariableProperties{
 			make(TypeMap),
 			make(AutoTypeConvMap),
@@ -38,8 +108,9 @@ func TypeCheck(program *ast.Program) *VariableProperties {
 		},
 	}
 
-	for _, x := range program.Contents.Body {
-		c.inferStmt(x)
-	}
+	c.linkClasses()
+	c.inferBody(program.Contents.Body)
+	c.unusedVars(program.Contents.Scope)
+	c.unusedFuncs()
 	return c.VariableProperties
 }
@@ -0,0 +1,446 @@
+package checker
+
+import (
+	"sulfur/src/ast"
+	"sulfur/src/builtins"
+	. "sulfur/src/errors"
+	"sulfur/src/lexer"
+	"sulfur/src/location"
+	"sulfur/src/typing"
+	"sulfur/src/utils"
+)
+
+func (c *checker) classOf(typ typing.Type) (*builtins.ClassSignature, bool) {
+	for i := range c.program.Classes {
+		if c.program.Classes[i].Name == string(typ) {
+			return &c.program.Classes[i], true
+		}
+	}
+	return nil, false
+}
+
+// Class instances are already passed around by reference
+func (c *checker) referable(typ typing.Type, loc *location.Location) bool {
+	if _, ok := c.classOf(typ); ok {
+		Errors.Error(ClassReference, "Cannot reference an instance of "+string(typ)+", as it is already passed by reference", loc)
+		return false
+	}
+	return true
+}
+
+// Private members can only be used from inside of the class that defines them, not even from classes extending it
+func (c *checker) visible(owner *builtins.ClassSignature, vis lexer.TokenType, kind, name string, loc *location.Location) bool {
+	if vis == lexer.Private && (c.class == nil || c.class.Name != owner.Name) {
+		Errors.Error(PrivateMember, "The "+kind+" "+name+" of "+owner.Name+" is private", loc)
+		return false
+	}
+	return true
+}
+
+// Resolves the parent of every class, leaving out any that would make a class extend itself
+func (c *checker) linkClasses() {
+	for i := range c.program.Classes {
+		class := &c.program.Classes[i]
+		if class.Extends == "" {
+			continue
+		}
+
+		parent, ok := c.classOf(typing.Type(class.Extends))
+		if ok && !parent.Is(class) {
+			class.Parent = parent
+		}
+	}
+
+	// Instances can be used wherever any of their ancestors are expected
+	for _, class := range c.program.Classes {
+		for parent := class.Parent; parent != nil; parent = parent.Parent {
+			conv := builtins.QuickTypeConv(typing.Type(class.Name), typing.Type(parent.Name))
+			c.program.TypeConvs = append(c.program.TypeConvs, conv)
+		}
+	}
+}
+
+func (c *checker) super(expr ast.Expr) bool {
+	iden, ok := expr.(ast.Identifier)
+	return ok && iden.Name == "super" && c.class != nil
+}
+
+// Counts a method as used, along with everything overriding it, since any of them could be called
+func (c *checker) use(class *builtins.ClassSignature, method *builtins.MethodSignature) {
+	method.Uses++
+	for i := range c.program.Classes {
+		child := &c.program.Classes[i]
+		if child == class || !child.Is(class) {
+			continue
+		}
+		if override, ok := child.Own(method.Name); ok {
+			override.Uses++
+		}
+	}
+}
+
+func (c *checker) inferClass(x ast.Class) {
+	class, _ := c.classOf(typing.Type(x.Name.Name))
+
+	if !ast.Empty(x.Extends) && c.known(x.Extends) {
+		if _, ok := c.classOf(typing.Type(x.Extends.Name)); !ok {
+			Errors.Error(NotAnObject, "Cannot extend "+x.Extends.Name+", as it is not a class", x.Extends.Loc())
+		} else if class.Parent == nil {
+			Errors.Error(CyclicInheritance, class.Name+" cannot extend "+x.Extends.Name+", as "+x.Extends.Name+" already extends "+class.Name, x.Extends.Loc())
+		}
+	}
+
+	defined := map[string]*location.Location{}
+	define := func(name ast.Identifier) {
+		if prev, ok := defined[name.Name]; ok {
+			Errors.Error(AlreadyDefined, name.Name+" is already defined in "+class.Name, name.Loc(), Note("previously defined here", prev))
+		}
+		defined[name.Name] = name.Loc()
+	}
+
+	for _, field := range x.Fields {
+		define(field.Name)
+		c.known(field.Type)
+		if class.Parent != nil && class.Parent.Owner(field.Name.Name) != nil {
+			Errors.Error(AlreadyDefined, field.Name.Name+" is already defined in "+class.Parent.Owner(field.Name.Name).Name, field.Name.Loc())
+		}
+	}
+	for _, method := range x.Methods {
+		define(method.Name)
+		if class.Parent != nil {
+			c.inferOverride(class, method)
+		}
+	}
+
+	for _, op := range x.Operations {
+		c.inferOperation(op)
+	}
+
+	prev := c.class
+	c.class = class
+	for _, method := range x.Methods {
+		c.inferFuncBody(method.Params, method.Return, method.FuncScope, method.Body)
+	}
+	for _, newdel := range []ast.NewDel{x.New, x.Del} {
+		if !ast.Empty(newdel) {
+			c.inferFuncBody(newdel.Params, nil, newdel.FuncScope, newdel.Body)
+		}
+	}
+
+	converted := map[string]*location.Location{}
+	for _, to := range x.Conversions {
+		if prev, ok := converted[to.Type.Name]; ok {
+			Errors.Error(AlreadyDefined, class.Name+" already has a conversion to "+to.Type.Name, to.Loc(), Note("previously defined here", prev))
+		} else {
+			converted[to.Type.Name] = to.Loc()
+		}
+		c.inferTo(class, to)
+	}
+	c.class = prev
+}
+
+// Overriding methods must be called the same way as the methods they replace
+func (c *checker) inferOverride(class *builtins.ClassSignature, x ast.Method) {
+	name := x.Name.Name
+	if _, _, ok := class.Parent.Field(name); ok {
+		Errors.Error(AlreadyDefined, name+" is already defined as a field in "+class.Parent.Owner(name).Name, x.Name.Loc())
+		return
+	}
+
+	parent, ok := class.Parent.Method(name)
+	if !ok {
+		return
+	}
+	method, _ := class.Own(name)
+
+	same := parent.Return == method.Return && len(parent.Params) == len(method.Params)
+	for i := 0; same && i < len(parent.Params); i++ {
+		same = parent.Params[i].Type == method.Params[i].Type && parent.Params[i].Referenced == method.Params[i].Referenced
+	}
+	if !same {
+		Errors.Error(InvalidOverride, name+" must have the same parameters and return type as the method it overrides in "+class.Parent.Owner(name).Name, x.Name.Loc())
+	}
+}
+
+func (c *checker) inferNew(x ast.New) typing.Type {
+	typ := typing.Type(x.Class.Name)
+	if !c.known(x.Class) {
+		for _, param := range *x.Params {
+			c.inferExpr(param)
+		}
+		return c.typ(x, typing.Invalid)
+	}
+
+	class, ok := c.classOf(typ)
+	if !ok {
+		Errors.Error(NotAnObject, "Cannot create an instance of "+typ.String()+", as it is not a class", x.Class.Loc())
+		return c.typ(x, typing.Invalid)
+	}
+
+	if owner, ctor, ok := class.Constructor(); ok {
+		c.visible(owner, ctor.Visibility, "constructor", "new", x.Loc())
+		c.inferParams(ctor.Params, *x.Params, x.Loc())
+		ctor.Uses++
+	} else {
+		c.inferParams([]builtins.ParamSignature{}, *x.Params, x.Loc())
+	}
+	for parent := class; parent != nil; parent = parent.Parent {
+		if dtor, ok := parent.Own("del"); ok {
+			dtor.Uses++
+		}
+	}
+
+	return c.typ(x, typ)
+}
+
+// Finds the class that a member is being accessed on, which is the current instance if there is no parent
+func (c *checker) inferParent(x ast.Access) (*builtins.ClassSignature, bool) {
+	if ast.Empty(x.Parent) {
+		if c.class == nil {
+			Errors.Error(SelfOutsideClass, "Cannot access ."+x.Child.Name+" outside of a class", x.Loc())
+			return nil, false
+		}
+		return c.class, true
+	}
+	if c.super(x.Parent) {
+		if c.class.Parent == nil {
+			Errors.Error(NoParent, c.class.Name+" does not extend another class, so it has no super", x.Parent.Loc())
+			return nil, false
+		}
+		return c.class.Parent, true
+	}
+
+	return c.objectOf(c.inferExpr(x.Parent), x)
+}
+
+func (c *checker) objectOf(typ typing.Type, x ast.Access) (*builtins.ClassSignature, bool) {
+	if !c.valued(typ, x.Parent) {
+		return nil, false
+	}
+
+	class, ok := c.classOf(typ)
+	if !ok {
+		Errors.Error(NotAnObject, "Cannot access "+x.Child.Name+" on "+typ.String()+", as it is not a class", x.Loc())
+		return nil, false
+	}
+	return class, true
+}
+
+func (c *checker) members(class *builtins.ClassSignature) []string {
+	names := []string{}
+	for _, field := range class.AllFields() {
+		names = append(names, field.Name)
+	}
+	for parent := class; parent != nil; parent = parent.Parent {
+		for _, method := range parent.Methods {
+			names = append(names, method.Name)
+		}
+	}
+	return names
+}
+
+func (c *checker) inferField(x ast.Access) (*builtins.ClassSignature, *builtins.FieldSignature, bool) {
+	class, ok := c.inferParent(x)
+	if !ok {
+		return nil, nil, false
+	}
+	return c.field(class, x)
+}
+
+func (c *checker) field(class *builtins.ClassSignature, x ast.Access) (*builtins.ClassSignature, *builtins.FieldSignature, bool) {
+	_, field, ok := class.Field(x.Child.Name)
+	if !ok {
+		if _, ok := class.Method(x.Child.Name); ok {
+			Errors.Error(UndefinedMember, x.Child.Name+" is a method of "+class.Name+", and must be called", x.Child.Loc())
+		} else {
+			Errors.Error(UndefinedMember, class.Name+" has no field "+x.Child.Name+utils.Suggest(x.Child.Name, c.members(class)), x.Child.Loc())
+		}
+		return nil, nil, false
+	}
+
+	if !c.visible(class.Owner(field.Name), field.Visibility, "field", field.Name, x.Child.Loc()) {
+		return nil, nil, false
+	}
+	return class, field, true
+}
+
+func (c *checker) inferAccess(x ast.Access) typing.Type {
+	if enum, ok := c.enumAccess(x); ok {
+		return c.inferEnumAccess(enum, x)
+	}
+
+	// .self is the current instance, and super.self is the same instance as its parent class
+	if x.Child.Name == "self" && (ast.Empty(x.Parent) || c.super(x.Parent)) {
+		class, ok := c.inferParent(x)
+		if !ok {
+			return c.typ(x, typing.Invalid)
+		}
+		if _, _, ok := class.Field("self"); !ok {
+			return c.typ(x, typing.Type(class.Name))
+		}
+	}
+
+	// Arrays, lists and maps only have a length, so the parent is inferred before knowing whether it is an object
+	var field *builtins.FieldSignature
+	var ok bool
+	if !ast.Empty(x.Parent) && !c.super(x.Parent) {
+		typ := c.inferExpr(x.Parent)
+		if _, isArray := typ.Item(); isArray {
+			return c.inferLength(x, "Arrays")
+		}
+		if _, isList := typ.ListItem(); isList {
+			return c.inferLength(x, "Lists")
+		}
+		if _, _, isMap := typ.Entry(); isMap {
+			return c.inferLength(x, "Maps")
+		}
+		class, isObject := c.objectOf(typ, x)
+		if !isObject {
+			return c.typ(x, typing.Invalid)
+		}
+		_, field, ok = c.field(class, x)
+	} else {
+		_, field, ok = c.inferField(x)
+	}
+
+	if !ok {
+		return c.typ(x, typing.Invalid)
+	}
+	return c.typ(x, field.Type)
+}
+
+func (c *checker) inferMethodCall(x ast.MethodCall) typing.Type {
+	name := x.Method.Child
+	var class *builtins.ClassSignature
+	var ok bool
+	if !ast.Empty(x.Method.Parent) && !c.super(x.Method.Parent) {
+		typ := c.inferExpr(x.Method.Parent)
+		if item, isList := typ.ListItem(); isList {
+			return c.inferContainerMethod(x, "Lists", builtins.ListMethods(item))
+		}
+		if key, val, isMap := typ.Entry(); isMap {
+			return c.inferContainerMethod(x, "Maps", builtins.MapMethods(key, val))
+		}
+		class, ok = c.objectOf(typ, x.Method)
+	} else {
+		class, ok = c.inferParent(x.Method)
+	}
+	if !ok {
+		for _, param := range *x.Params {
+			c.inferExpr(param)
+		}
+		return c.typ(x, typing.Invalid)
+	}
+
+	// The parent's constructor can be called explicitly through super, but never through an instance
+	super := c.super(x.Method.Parent)
+	method, ok := class.Method(name.Name)
+	owner := class
+	if super && name.Name == "new" {
+		owner, method, ok = class.Constructor()
+	} else if ok {
+		owner = class.Owner(method.Name)
+	}
+	if !ok || method.Name == "del" || method.Name == "new" && !super {
+		if _, _, ok := class.Field(name.Name); ok {
+			Errors.Error(UndefinedMember, name.Name+" is a field of "+class.Name+", not a method", name.Loc())
+		} else {
+			Errors.Error(UndefinedMember, class.Name+" has no method "+name.Name+utils.Suggest(name.Name, c.members(class)), name.Loc())
+		}
+		for _, param := range *x.Params {
+			c.inferExpr(param)
+		}
+		return c.typ(x, typing.Invalid)
+	}
+
+	c.visible(owner, method.Visibility, "method", method.Name, name.Loc())
+	c.inferParams(method.Params, *x.Params, x.Loc())
+	if super {
+		method.Uses++
+	} else {
+		c.use(class, method)
+	}
+
+	return c.typ(x, method.Return)
+}
+
+// Fields declared with val can be read from anywhere, but only changed from inside of their class
+func (c *checker) inferFieldAssignment(x ast.FieldAssignment) {
+	class, field, ok := c.inferField(x.Field)
+	val := c.inferExpr(x.Value)
+	if !ok || !c.valued(val, x.Value) {
+		return
+	}
+	c.typ(x.Field, field.Type)
+
+	if field.Visibility == lexer.Value && (c.class == nil || !c.class.Is(class.Owner(field.Name))) {
+		Errors.Error(ReadOnlyField, "The field "+field.Name+" of "+class.Name+" can only be changed from inside of the class", x.Field.Loc())
+	}
+
+	if !lexer.Empty(x.Op) && field.Type != val && c.compound(x.Op, field.Type, val, x.Value) {
+		return
+	}
+
+	if field.Type != val {
+		conv, ok := c.AutoSingleInfer(val, field.Type, x.Value)
+		if ok {
+			val, _ = AutoSwitch(val, field.Type, conv)
+		} else {
+			Errors.Error(MismatchedTypes, "Expected "+field.Type.String()+", but got "+val.String()+" instead", x.Value.Loc())
+			return
+		}
+	}
+
+	if lexer.Empty(x.Op) || c.compound(x.Op, field.Type, val, x.Value) {
+		return
+	}
+
+	Errors.Error(UndefinedOperation, "No operation "+x.Op.Value+" exists for "+field.Type.String()+" and "+val.String(), x.Op.Location)
+}
+
+func (c *checker) inferTo(class *builtins.ClassSignature, x ast.To) {
+	typ := typing.Type(x.Type.Name)
+	if !c.known(x.Type) {
+		x.FuncScope.Return = typing.Invalid
+	} else if to, ok := c.classOf(typ); ok && class.Is(to) {
+		Errors.Error(AlreadyDefined, "Every "+class.Name+" can already be converted to "+to.Name, x.Loc())
+	}
+
+	c.inferFuncBody([]ast.Param{}, nil, x.FuncScope, x.Body)
+}
+
+// Tests at runtime whether an instance is of a class, or of a class extending it
+func (c *checker) inferIs(x ast.Comparison) typing.Type {
+	typ := c.inferExpr(x.Left)
+	if !c.valued(typ, x.Left) {
+		return c.typ(x, typing.Invalid)
+	}
+
+	iden, ok := x.Right.(ast.Identifier)
+	if !ok {
+		Errors.Error(NotAnObject, "Expected the name of a class after is", x.Right.Loc())
+		return c.typ(x, typing.Invalid)
+	}
+	if !c.known(iden) {
+		return c.typ(x, typing.Invalid)
+	}
+
+	class, ok := c.classOf(typ)
+	if !ok {
+		Errors.Error(NotAnObject, "Cannot test the class of "+typ.String()+", as it is not a class", x.Left.Loc())
+		return c.typ(x, typing.Invalid)
+	}
+	target, ok := c.classOf(typing.Type(iden.Name))
+	if !ok {
+		Errors.Error(NotAnObject, "Cannot test if a value is "+iden.Name+", as it is not a class", iden.Loc())
+		return c.typ(x, typing.Invalid)
+	}
+
+	if !target.Is(class) && !class.Is(target) {
+		Errors.Error(MismatchedTypes, "An instance of "+class.Name+" can never be "+target.Name, x.Loc())
+		return c.typ(x, typing.Invalid)
+	}
+
+	c.typ(x.Right, typing.Type(target.Name))
+	return c.typ(x, typing.Boolean)
+}
@@ -0,0 +1,91 @@
+package checker
+
+import (
+	"sulfur/src/ast"
+	"sulfur/src/builtins"
+	. "sulfur/src/errors"
+	"sulfur/src/location"
+	"sulfur/src/typing"
+	"sulfur/src/utils"
+)
+
+// Types that enums can hold, where only the integral ones are numbered automatically
+var enumTypes = []typing.Type{typing.Integer, typing.Unsigned, typing.Float, typing.Boolean, typing.String}
+
+func (c *checker) enumOf(typ typing.Type) (*builtins.EnumSignature, bool) {
+	for i := range c.program.Enums {
+		if c.program.Enums[i].Name == string(typ) {
+			return &c.program.Enums[i], true
+		}
+	}
+	return nil, false
+}
+
+// Elements are accessed through the name of their enum, like Season.Winter
+func (c *checker) enumAccess(x ast.Access) (*builtins.EnumSignature, bool) {
+	iden, ok := x.Parent.(ast.Identifier)
+	if !ok {
+		return nil, false
+	}
+	return c.enumOf(typing.Type(iden.Name))
+}
+
+func (c *checker) inferEnumAccess(enum *builtins.EnumSignature, x ast.Access) typing.Type {
+	if !utils.Contains(enum.Elems, x.Child.Name) {
+		Errors.Error(UndefinedMember, enum.Name+" has no value "+x.Child.Name+utils.Suggest(x.Child.Name, enum.Elems), x.Child.Loc())
+		return c.typ(x, typing.Invalid)
+	}
+	return c.typ(x, typing.Type(enum.Name))
+}
+
+func (c *checker) inferEnum(x ast.Enum) {
+	enum, _ := c.enumOf(typing.Type(x.Name.Name))
+	if !ast.Empty(x.From) && c.known(x.From) && !utils.Contains(enumTypes, enum.Type) {
+		Errors.Error(InvalidEnum, "Enums can only hold values of type int, uint, float, bool or string", x.From.Loc())
+		return
+	}
+
+	elems := map[string]*location.Location{}
+	var assigned, unassigned ast.Expr
+	for i, elem := range x.Elems {
+		if prev, ok := elems[elem.Name]; ok {
+			Errors.Error(AlreadyDefined, elem.Name+" is already defined in "+enum.Name, elem.Loc(), Note("previously defined here", prev))
+		} else {
+			elems[elem.Name] = elem.Loc()
+		}
+
+		val := x.Values[i]
+		if ast.Empty(val) {
+			if unassigned == nil {
+				unassigned = elem
+			}
+			continue
+		}
+		if assigned == nil {
+			assigned = val
+		}
+
+		typ := c.inferExpr(val)
+		if !c.valued(typ, val) {
+			continue
+		}
+		if ast.LiteralType(val) == typing.Invalid {
+			Errors.Error(InvalidEnum, "The values of an enum must be literals", val.Loc())
+			continue
+		}
+
+		// Positive integers can also be used for unsigned and floating point enums
+		if _, ok := val.(ast.Integer); ok && (enum.Type == typing.Unsigned || enum.Type == typing.Float) {
+			continue
+		}
+		if typ != enum.Type {
+			Errors.Error(MismatchedTypes, "Expected "+enum.Type.String()+", but got "+typ.String()+" instead", val.Loc())
+		}
+	}
+
+	if assigned != nil && unassigned != nil {
+		Errors.Error(InvalidEnum, "Every value of "+enum.Name+" must be given, as some of them are", unassigned.Loc(), Note("given here", assigned.Loc()))
+	} else if assigned == nil && enum.Type != typing.Integer && enum.Type != typing.Unsigned {
+		Errors.Error(InvalidEnum, "The values of "+enum.Name+" must be given, as only integers are numbered automatically", x.Name.Loc())
+	}
+}
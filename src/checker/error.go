@@ -0,0 +1,109 @@
+package checker
+
+import (
+	"sulfur/src/ast"
+	. "sulfur/src/errors"
+	"sulfur/src/location"
+	"sulfur/src/typing"
+)
+
+func (c *checker) isError(typ typing.Type) bool {
+	enum, ok := c.enumOf(typ)
+	return ok && enum.Error
+}
+
+func (c *checker) inferThrows(x ast.Function) {
+	if ast.Empty(x.Throws) {
+		return
+	}
+	if !c.known(x.Throws) {
+		x.FuncScope.Throws = typing.Invalid
+	} else if !c.isError(x.FuncScope.Throws) {
+		Errors.Error(NotAnError, x.Throws.Name+" is not an error type, so it cannot be thrown", x.Throws.Loc())
+		x.FuncScope.Throws = typing.Invalid
+	}
+}
+
+func (c *checker) inferThrow(x ast.Throw) {
+	typ := c.inferExpr(x.Value)
+	if !c.valued(typ, x.Value) {
+		return
+	}
+	if !c.isError(typ) {
+		Errors.Error(NotAnError, "Cannot throw "+typ.String()+", as it is not an error type", x.Value.Loc())
+		return
+	}
+
+	switch throws := c.topfun.Throws; {
+	case throws == typ || throws == typing.Invalid:
+	case throws == "":
+		Errors.Error(UnhandledError, "Cannot throw "+typ.String()+" outside of a function declared with throws "+typ.String(), x.Loc())
+	default:
+		Errors.Error(UnhandledError, "Cannot throw "+typ.String()+" from a function that throws "+throws.String(), x.Loc())
+	}
+}
+
+// Errors that are not caught are thrown on, which the function they leave has to be declared with
+func (c *checker) throws(typ typing.Type, what string, loc *location.Location) {
+	throws := c.topfun.Throws
+	switch {
+	case throws == typ || throws == typing.Invalid:
+	case c.topfun.Parent == nil:
+		Errors.Error(UnhandledError, what+", so it has to be caught", loc)
+	case throws == "":
+		Errors.Error(UnhandledError, what+", so it has to be caught, or the function has to be declared with throws "+typ.String(), loc)
+	default:
+		Errors.Error(UnhandledError, what+", so it has to be caught, as the function only throws "+throws.String(), loc)
+	}
+}
+
+// A try leaves the rest of its statement when the error is thrown on, so it can only be where nothing else follows it
+func (c *checker) inferValue(expr ast.Expr) typing.Type {
+	if x, ok := expr.(ast.Try); ok {
+		return c.inferTry(x, true)
+	}
+	return c.inferExpr(expr)
+}
+
+func (c *checker) inferTry(x ast.Try, value bool) typing.Type {
+	call, ok := x.Value.(ast.FuncCall)
+	if !ok {
+		c.inferExpr(x.Value)
+		Errors.Error(InvalidTry, "Only calls to functions that throw can be tried", x.Value.Loc())
+		return c.typ(x, typing.Invalid)
+	}
+
+	c.trying = true
+	ret := c.inferFuncCall(call)
+	var typ typing.Type
+	for _, fun := range c.program.Functions {
+		if fun.Name == call.Func.Name {
+			typ = fun.Throws
+			break
+		}
+	}
+	if typ == "" {
+		if ret != typing.Invalid {
+			Errors.Error(InvalidTry, call.Func.Name+" cannot throw, so it does not need to be tried", x.Loc())
+		}
+		return c.typ(x, ret)
+	}
+
+	switch {
+	case x.Arms != nil:
+		if value {
+			Errors.Error(InvalidTry, "A try with catch arms has no value, so it can only be used on its own", x.Loc())
+		}
+		c.inferArms(*x.Arms, typ, typ != typing.Invalid, "catch", x.Loc())
+	case !ast.Empty(x.Catch):
+		val := c.inferExpr(x.Catch)
+		if ret == typing.Void {
+			Errors.Error(InvalidTry, call.Func.Name+" gives no value, so there is nothing for catch to replace", x.Catch.Loc())
+		} else if ret != typing.Invalid {
+			c.matches(val, ret, x.Catch)
+		}
+	default:
+		c.throws(typ, call.Func.Name+" can throw "+typ.String(), x.Loc())
+	}
+	return c.typ(x, ret)
+}
@@ -3,12 +3,18 @@ package checker
 import (
 	"fmt"
 	"sulfur/src/ast"
+	"sulfur/src/builtins"
 	. "sulfur/src/errors"
+	"sulfur/src/lexer"
+	"sulfur/src/location"
 	"sulfur/src/typing"
+	"sulfur/src/utils"
 )
 
 func (c *checker) inferExpr(expr ast.Expr) typing.Type {
 	switch x := expr.(type) {
+	case ast.NoExpr:
+		return c.typ(x, typing.Invalid)
 	case ast.Identifier:
 		return c.inferIdentifier(x)
 	case ast.Integer:
@@ -34,6 +40,29 @@ func (c *checker) inferExpr(expr ast.Expr) typing.Type {
 		return c.inferFuncCall(x)
 	case ast.Reference:
 		return c.inferReference(x)
+	case ast.New:
+		return c.inferNew(x)
+	case ast.Access:
+		return c.inferAccess(x)
+	case ast.Array:
+		return c.inferArray(x)
+	case ast.Index:
+		return c.inferIndex(x)
+	case ast.List:
+		return c.inferList(x)
+	case ast.Map:
+		return c.inferMap(x)
+	case ast.Tuple:
+		return c.inferTuple(x)
+	case ast.Spread:
+		return c.typ(x, c.inferExpr(x.Value))
+	case ast.MethodCall:
+		return c.inferMethodCall(x)
+	case ast.Lambda:
+		return c.inferLambda(x)
+	case ast.Try:
+		Errors.Error(InvalidTry, "A try can only be used on its own, or as the whole value of a declaration, an assignment or a return", x.Loc())
+		return c.inferTry(x, true)
 	default:
 		fmt.Println("Ignored type inferring expression")
 		return c.typ(x, typing.Void)
@@ -41,40 +70,55 @@ func (c *checker) inferExpr(expr ast.Expr) typing.Type {
 }
 
 func (c *checker) inferIdentifier(x ast.Identifier) typing.Type {
-	return c.typ(x, c.top.Lookup(x.Name, x.Pos).Type)
+	vari, ok := c.find(x.Name)
+	if !ok {
+		if typ, ok := c.funcValue(x); ok {
+			return c.typ(x, typ)
+		}
+		vari = c.top.Lookup(x.Name, x.Pos)
+	}
+	vari.Uses++
+
+	return c.typ(x, vari.Type)
 }
 
 func (c *checker) inferBinaryOp(x ast.BinaryOp) typing.Type {
 	left := c.inferExpr(x.Left)
 	right := c.inferExpr(x.Right)
+	validLeft, validRight := c.valued(left, x.Left), c.valued(right, x.Right)
+	if !validLeft || !validRight {
+		return c.typ(x, typing.Invalid)
+	}
+
+	// Operators can be defined for operands of different types, so those are looked for before converting
+	if ret, ok := c.binop(x.Op.Type, left, right); ok {
+		return c.typ(x, ret)
+	}
+
 	if left != right {
 		conv, ok := c.AutoInfer(left, right, x.Left, x.Right)
 		if ok {
 			left, right = AutoSwitch(left, right, conv)
 		} else {
-			Errors.Error("Expected "+left.String()+", but got "+right.String()+" instead", x.Right.Loc())
+			Errors.Error(MismatchedTypes, "Expected "+left.String()+", but got "+right.String()+" instead", x.Right.Loc())
+			return c.typ(x, typing.Invalid)
 		}
 	}
 
-	for i, binop := range c.program.BinaryOps {
-		if binop.Op != x.Op.Type {
-			continue
-		}
-
-		if binop.Left == left && binop.Right == right {
-			binop.Uses++
-			c.program.BinaryOps[i] = binop
-
-			return c.typ(x, binop.Return)
-		}
+	if ret, ok := c.binop(x.Op.Type, left, right); ok {
+		return c.typ(x, ret)
 	}
 
-	Errors.Error("No operation "+x.Op.Value+" exists for "+left.String()+" and "+right.String(), x.Op.Location)
-	return c.typ(x, typing.Void)
+	Errors.Error(UndefinedOperation, "No operation "+x.Op.Value+" exists for "+left.String()+" and "+right.String(), x.Op.Location)
+	return c.typ(x, typing.Invalid)
 }
 
 func (c *checker) inferUnaryOp(x ast.UnaryOp) typing.Type {
 	val := c.inferExpr(x.Value)
+	if !c.valued(val, x.Value) {
+		return c.typ(x, typing.Invalid)
+	}
+
 	for i, unop := range c.program.UnaryOps {
 		if unop.Op != x.Op.Type {
 			continue
@@ -88,16 +132,25 @@ func (c *checker) inferUnaryOp(x ast.UnaryOp) typing.Type {
 		}
 	}
 
-	Errors.Error("No operation "+x.Op.Value+" exists for "+val.String(), x.Op.Location)
-	return c.typ(x, typing.Void)
+	Errors.Error(UndefinedOperation, "No operation "+x.Op.Value+" exists for "+val.String(), x.Op.Location)
+	return c.typ(x, typing.Invalid)
 }
 
 func (c *checker) inferComparison(x ast.Comparison) typing.Type {
+	if x.Comp.Type == lexer.Is {
+		return c.inferIs(x)
+	}
+
 	left := c.inferExpr(x.Left)
 	right := c.inferExpr(x.Right)
+	validLeft, validRight := c.valued(left, x.Left), c.valued(right, x.Right)
+	if !validLeft || !validRight {
+		return c.typ(x, typing.Invalid)
+	}
 
 	if left != right {
-		Errors.Error("Expected "+left.String()+", but got "+right.String()+" instead", x.Right.Loc())
+		Errors.Error(MismatchedTypes, "Expected "+left.String()+", but got "+right.String()+" instead", x.Right.Loc())
+		return c.typ(x, typing.Invalid)
 	}
 
 	for i, comp := range c.program.Comparisons {
@@ -113,14 +166,22 @@ func (c *checker) inferComparison(x ast.Comparison) typing.Type {
 		}
 	}
 
-	Errors.Error("No comparison "+x.Comp.Value+" exists for "+left.String()+" and "+right.String(), x.Comp.Location)
-	return c.typ(x, typing.Void)
+	Errors.Error(UndefinedComparison, "No comparison "+x.Comp.Value+" exists for "+left.String()+" and "+right.String(), x.Comp.Location)
+	return c.typ(x, typing.Invalid)
 }
 
 func (c *checker) inferTypeConv(x ast.TypeConv) typing.Type {
 	typ := c.inferExpr(x.Value)
+	if !c.valued(typ, x.Value) {
+		return c.typ(x, typing.Invalid)
+	}
+
+	if !c.known(x.Type) {
+		return c.typ(x, typing.Invalid)
+	}
+
 	if typ == typing.Type(x.Type.Name) {
-		Errors.Warn("Unnecessary type conversion from "+string(typ)+" to "+string(typ), x.Loc())
+		Errors.Warn(Conversion, "Unnecessary type conversion from "+string(typ)+" to "+string(typ), x.Loc())
 		return c.typ(x, typ)
 	}
 
@@ -133,51 +194,94 @@ func (c *checker) inferTypeConv(x ast.TypeConv) typing.Type {
 		}
 	}
 
-	Errors.Error("Cannot convert from "+typ.String()+" to "+x.Type.Name, x.Loc())
-	return c.typ(x, typing.Void)
+	Errors.Error(InvalidConversion, "Cannot convert from "+typ.String()+" to "+x.Type.Name, x.Loc())
+	return c.typ(x, typing.Invalid)
+}
+
+// A variadic parameter takes every argument left over, or a single array spread into it
+func (c *checker) inferParams(sigs []builtins.ParamSignature, params []ast.Expr, loc *location.Location) {
+	fixed, variadic := sigs, false
+	if len(sigs) > 0 && sigs[len(sigs)-1].Variadic {
+		fixed, variadic = sigs[:len(sigs)-1], true
+	}
+
+	l1, l2 := len(params), len(fixed)
+	if l1 < l2 || l1 > l2 && !variadic {
+		expected := fmt.Sprint(l2)
+		if variadic {
+			expected = "at least " + expected
+		}
+		if l1 == 0 {
+			Errors.Error(ArgumentCount, "No parameters given, but "+expected+" expected", loc)
+		} else {
+			// A value piped into a call comes before it, so the call itself is blamed instead
+			at := params[l1-1].Loc()
+			if at.Idx < loc.Idx {
+				at = loc
+			}
+			Errors.Error(ArgumentCount, fmt.Sprint(l1)+" parameters given, but "+expected+" expected", at)
+		}
+	}
+
+	for i, param := range params {
+		var sig builtins.ParamSignature
+		if i < l2 {
+			sig = fixed[i]
+		} else if variadic {
+			sig = sigs[l2]
+		}
+
+		if spread, ok := param.(ast.Spread); ok {
+			typ := c.inferExpr(param)
+			if !sig.Variadic || i != l2 || i != l1-1 {
+				Errors.Error(ArgumentCount, "Only the last argument can be spread, and only into a variadic parameter", spread.Loc())
+			} else if typ != sig.Type && c.valued(typ, spread.Value) {
+				Errors.Error(MismatchedTypes, "Expected "+sig.Type.String()+", but got "+typ.String()+" instead", spread.Value.Loc())
+			}
+			continue
+		}
+		if sig.Variadic {
+			item, _ := sig.Type.Item()
+			sig = builtins.QuickParam(item)
+		}
+
+		typ := c.inferExpr(param)
+		if sig.Type == typing.Void || !c.valued(typ, param) {
+			continue
+		}
+		paramTyp := sig.Type
+
+		paramRef := sig.Referenced
+		givenRef := c.Refs.Has(param)
+		if paramRef != givenRef {
+			if paramRef {
+				Errors.Error(MismatchedReference, "Expected &"+string(paramTyp)+", but got "+string(typ)+" instead", param.Loc())
+			} else {
+				Errors.Error(MismatchedReference, "Expected "+string(paramTyp)+", but got &"+string(typ)+" instead", param.Loc())
+			}
+		}
+
+		if typ != paramTyp {
+			conv, ok := c.AutoSingleInfer(typ, paramTyp, param)
+			if ok {
+				typ, paramTyp = AutoSwitch(typ, paramTyp, conv)
+			} else {
+				Errors.Error(MismatchedTypes, "Expected "+paramTyp.String()+", but got "+typ.String()+" instead", param.Loc())
+			}
+		}
+	}
 }
 
 func (c *checker) inferFuncCall(x ast.FuncCall) typing.Type {
+	tried := c.trying
+	c.trying = false
+
 	for i, fun := range c.program.Functions {
 		if fun.Name == x.Func.Name {
-			l1, l2 := len(*x.Params), len(fun.Params)
-			if l1 != l2 {
-				if l1 == 0 {
-					Errors.Error("No parameters given, but "+fmt.Sprint(l2)+" expected", x.Loc())
-				} else {
-					var param ast.Expr
-					if l1 < l2 {
-						param = (*x.Params)[l1-1]
-					} else {
-						param = (*x.Params)[l1-1]
-					}
-					Errors.Error(fmt.Sprint(l1)+" parameters given, but "+fmt.Sprint(l2)+" expected", param.Loc())
-				}
-			}
-
-			for i, param := range *x.Params {
-				typ := c.inferExpr(param)
-				paramTyp := fun.Params[i].Type
-
-				paramRef := fun.Params[i].Referenced
-				givenRef := c.Refs.Has(param)
-				if paramRef != givenRef {
-					if paramRef {
-						Errors.Error("Expected &"+string(paramTyp)+", but got "+string(typ)+" instead", param.Loc())
-					} else {
-						Errors.Error("Expected "+string(paramTyp)+", but got &"+string(typ)+" instead", param.Loc())
-					}
-				}
-
-				if typ != paramTyp {
-					conv, ok := c.AutoSingleInfer(typ, paramTyp, param)
-					if ok {
-						typ, paramTyp = AutoSwitch(typ, paramTyp, conv)
-					} else {
-						Errors.Error("Expected "+paramTyp.String()+", but got "+typ.String()+" instead", param.Loc())
-					}
-				}
+			if fun.Throws != "" && !tried {
+				Errors.Error(UnhandledError, fun.Name+" can throw "+fun.Throws.String()+", so it has to be called with try", x.Loc())
 			}
+			c.inferParams(fun.Params, *x.Params, x.Loc())
 
 			fun.Uses++
 			c.program.Functions[i] = fun
@@ -186,13 +290,34 @@ func (c *checker) inferFuncCall(x ast.FuncCall) typing.Type {
 		}
 	}
 
-	Errors.Error("The function "+x.Func.Name+" is undefined", x.Func.Pos)
-	return c.typ(x, typing.Void)
+	if vari, ok := c.find(x.Func.Name); ok {
+		return c.typ(x, c.inferValueCall(vari, x))
+	}
+
+	for _, param := range *x.Params {
+		c.inferExpr(param)
+	}
+
+	names := utils.Apply(c.program.Functions, func(fun builtins.FuncSignature) string {
+		return fun.Name
+	})
+	Errors.Error(UndefinedFunction, "The function "+x.Func.Name+" is undefined"+utils.Suggest(x.Func.Name, names), x.Func.Pos)
+	return c.typ(x, typing.Invalid)
 }
 
 func (c *checker) inferReference(x ast.Reference) typing.Type {
-	vari := c.top.Lookup(x.Variable.Name, x.Variable.Loc())
+	vari, ok := c.find(x.Variable.Name)
+	if !ok {
+		vari = c.top.Lookup(x.Variable.Name, x.Variable.Loc())
+	}
+	if vari.Type == typing.Invalid {
+		return c.typ(x, typing.Invalid)
+	}
 	vari.Referenced = true
+	vari.Uses++
+	if !c.referable(vari.Type, x.Loc()) {
+		return c.typ(x, typing.Invalid)
+	}
 
 	c.program.References.Add(vari.Type)
 	c.top.ActiveRefs = append(c.top.ActiveRefs, vari)
@@ -201,3 +326,20 @@ func (c *checker) inferReference(x ast.Reference) typing.Type {
 
 	return c.typ(x, vari.Type)
 }
+
+// Finds the operation for a pair of operands and counts it as used
+func (c *checker) binop(op lexer.TokenType, left, right typing.Type) (typing.Type, bool) {
+	for i, binop := range c.program.BinaryOps {
+		if binop.Op == op && binop.Left == left && binop.Right == right {
+			binop.Uses++
+			c.program.BinaryOps[i] = binop
+
+			// A swapped operation calls the one it was made from
+			if binop.Swapped {
+				c.binop(op, right, left)
+			}
+			return binop.Return, true
+		}
+	}
+	return typing.Invalid, false
+}
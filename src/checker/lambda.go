@@ -0,0 +1,123 @@
+package checker
+
+import (
+	"sulfur/src/ast"
+	"sulfur/src/builtins"
+	. "sulfur/src/errors"
+	"sulfur/src/typing"
+	"sulfur/src/utils"
+)
+
+// Finds a variable visible from the current scope, capturing it when it belongs outside of a lambda
+func (c *checker) find(name string) (*ast.Variable, bool) {
+	if vari, ok := c.top.Find(name); ok {
+		return vari, true
+	}
+	return c.capture(name, len(c.lambdas)-1)
+}
+
+// Captured variables are moved into a box shared with the lambda, which stands in for them with its own variable
+func (c *checker) capture(name string, depth int) (*ast.Variable, bool) {
+	if depth < 0 {
+		return nil, false
+	}
+	lambda := c.lambdas[depth]
+
+	outer, ok := lambda.Scope.Parent.Find(name)
+	if !ok {
+		outer, ok = c.capture(name, depth-1)
+	}
+	if !ok {
+		return nil, false
+	}
+
+	if !outer.References && !outer.Captured && outer.Type != typing.Invalid {
+		outer.Referenced = true
+		outer.Captured = true
+		c.program.References.Add(outer.Type)
+
+		// The box is let go of with the scope that declared the variable
+		scope := lambda.Scope.Parent
+		for scope.Vars[name] != outer {
+			scope = scope.Parent
+		}
+		scope.ActiveRefs = append(scope.ActiveRefs, outer)
+	}
+	outer.Uses++
+
+	inner := ast.NewVariable(lambda.FuncScope, outer.Pos, name, true, outer.Type, ast.Local)
+	lambda.Scope.Vars[name] = inner
+	lambda.FuncScope.Decls[inner] = nil
+	*lambda.Captures = append(*lambda.Captures, ast.Capture{Outer: outer, Inner: inner})
+	return inner, true
+}
+
+// Named functions can be used as values, as long as a function type can describe them
+func (c *checker) funcValue(x ast.Identifier) (typing.Type, bool) {
+	for i, fun := range c.program.Functions {
+		if fun.Name != x.Name {
+			continue
+		}
+		fun.Uses++
+		c.program.Functions[i] = fun
+
+		if fun.Throws != "" {
+			Errors.Error(FunctionValue, "The function "+fun.Name+" cannot be used as a value, as it can throw", x.Loc())
+			return typing.Invalid, true
+		}
+		params := []typing.Type{}
+		for _, param := range fun.Params {
+			if param.Referenced || param.Variadic {
+				Errors.Error(FunctionValue, "The function "+fun.Name+" cannot be used as a value, as it takes references or a variable number of arguments", x.Loc())
+				return typing.Invalid, true
+			}
+			params = append(params, param.Type)
+		}
+		return typing.Func(params, fun.Return), true
+	}
+	return "", false
+}
+
+// Variables holding a function are called through the signature of their type
+func (c *checker) inferValueCall(vari *ast.Variable, x ast.FuncCall) typing.Type {
+	vari.Uses++
+	params, ret, ok := vari.Type.Signature()
+	if !ok {
+		for _, param := range *x.Params {
+			c.inferExpr(param)
+		}
+		if vari.Type != typing.Invalid {
+			Errors.Error(NotAFunction, vari.Name+" is of type "+vari.Type.String()+", which cannot be called", x.Func.Loc(), Note("declared here", vari.Pos))
+		}
+		return typing.Invalid
+	}
+
+	c.inferParams(utils.Apply(params, builtins.QuickParam), *x.Params, x.Loc())
+	return ret
+}
+
+func (c *checker) inferLambda(x ast.Lambda) typing.Type {
+	class, top, topfun := c.class, c.top, c.topfun
+	c.lambdas = append(c.lambdas, x)
+	c.class, c.top, c.topfun = nil, x.Scope, x.FuncScope
+
+	c.declareParams(*x.Params)
+	ret := c.inferExpr(x.Body)
+	x.FuncScope.Return = ret
+	c.unusedVars(x.Scope)
+
+	c.class, c.top, c.topfun = class, top, topfun
+	c.lambdas = c.lambdas[:len(c.lambdas)-1]
+
+	params := []typing.Type{}
+	valid := ret != typing.Invalid
+	for _, param := range *x.Params {
+		typ := x.Scope.Vars[param.Name.Name].Type
+		valid = valid && typ != typing.Invalid
+		params = append(params, typ)
+	}
+	if !valid {
+		return c.typ(x, typing.Invalid)
+	}
+	return c.typ(x, typing.Func(params, ret))
+}
@@ -0,0 +1,41 @@
+package checker
+
+import (
+	"sulfur/src/ast"
+	"sulfur/src/builtins"
+	. "sulfur/src/errors"
+	"sulfur/src/typing"
+	"sulfur/src/utils"
+)
+
+func (c *checker) inferList(x ast.List) typing.Type {
+	known := c.known(x.Type)
+	typ := typing.Type(x.Type.Name)
+	for _, item := range *x.Items {
+		c.inferEntry(item, typ, known)
+	}
+
+	if !known {
+		return c.typ(x, typing.Invalid)
+	}
+	return c.typ(x, typing.List(typ))
+}
+
+// Lists and maps have a fixed set of methods, given by the types they hold
+func (c *checker) inferContainerMethod(x ast.MethodCall, kind string, methods []builtins.MethodSignature) typing.Type {
+	name := x.Method.Child
+	method, ok := builtins.FindMethod(methods, name.Name)
+	if !ok {
+		names := utils.Apply(methods, func(method builtins.MethodSignature) string {
+			return method.Name
+		})
+		Errors.Error(UndefinedMember, kind+" have no method "+name.Name+utils.Suggest(name.Name, names), name.Loc())
+		for _, param := range *x.Params {
+			c.inferExpr(param)
+		}
+		return c.typ(x, typing.Invalid)
+	}
+
+	c.inferParams(method.Params, *x.Params, x.Loc())
+	return c.typ(x, method.Return)
+}
@@ -0,0 +1,41 @@
+package checker
+
+import (
+	"sulfur/src/ast"
+	. "sulfur/src/errors"
+	"sulfur/src/typing"
+	"sulfur/src/utils"
+)
+
+// Types that the runtime can hash and compare, besides enums which are hashed like integers
+var keyTypes = []typing.Type{typing.Integer, typing.Unsigned, typing.Float, typing.Boolean, typing.String}
+
+func (c *checker) hashable(key typing.Type) bool {
+	_, enum := c.enumOf(key)
+	return enum || utils.Contains(keyTypes, key)
+}
+
+func (c *checker) inferMap(x ast.Map) typing.Type {
+	known := c.known(ast.Identifier{Pos: x.Key.Pos, Name: string(typing.Map(typing.Type(x.Key.Name), typing.Type(x.Value.Name)))})
+	key, val := typing.Type(x.Key.Name), typing.Type(x.Value.Name)
+	for i := range *x.Keys {
+		c.inferEntry((*x.Keys)[i], key, known)
+		c.inferEntry((*x.Values)[i], val, known)
+	}
+
+	if !known {
+		return c.typ(x, typing.Invalid)
+	}
+	return c.typ(x, typing.Map(key, val))
+}
+
+func (c *checker) inferEntry(expr ast.Expr, typ typing.Type, known bool) {
+	val := c.inferExpr(expr)
+	if !known || !c.valued(val, expr) || val == typ {
+		return
+	}
+
+	if _, ok := c.AutoSingleInfer(val, typ, expr); !ok {
+		Errors.Error(MismatchedTypes, "Expected "+typ.String()+", but got "+val.String()+" instead", expr.Loc())
+	}
+}
@@ -0,0 +1,142 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+	"sulfur/src/ast"
+	. "sulfur/src/errors"
+	"sulfur/src/lexer"
+	"sulfur/src/location"
+	"sulfur/src/typing"
+)
+
+func (c *checker) inferMatch(x ast.Match) {
+	typ := c.inferExpr(x.Value)
+	c.inferArms(*x.Arms, typ, c.valued(typ, x.Value), "match", x.Value.Loc())
+}
+
+// Arms are shared by matches and catches, which are named by what for the errors
+func (c *checker) inferArms(arms []ast.Arm, typ typing.Type, valid bool, what string, loc *location.Location) {
+	seen := map[string]*location.Location{}
+	exhaustive := false
+	for _, arm := range arms {
+		if len(*arm.Patterns) == 0 {
+			exhaustive = true
+		}
+
+		for _, pattern := range *arm.Patterns {
+			c.inferPattern(pattern, typ, valid)
+
+			key, ok := constantPattern(pattern)
+			if !ok {
+				continue
+			}
+			if prev, ok := seen[key]; ok {
+				Errors.Warn(Unreachable, "Unreachable pattern, as "+key+" is already matched", pattern.Loc(), Note("matched here", prev))
+			} else {
+				seen[key] = pattern.Loc()
+			}
+		}
+
+		c.inferBlock(arm.Body, nil)
+	}
+
+	// Every value of an enum has to be handled, unless an else arm handles the rest
+	enum, ok := c.enumOf(typ)
+	if !ok || exhaustive {
+		return
+	}
+	missing := []string{}
+	for _, elem := range enum.Elems {
+		if _, ok := seen[enum.Name+"."+elem]; !ok {
+			missing = append(missing, enum.Name+"."+elem)
+		}
+	}
+	if len(missing) > 0 {
+		Errors.Error(NonExhaustive, "The "+what+" does not handle "+strings.Join(missing, ", ")+", so it needs an arm for them or an else arm", loc)
+	}
+}
+
+// Patterns are either ranges, the names of classes to test instances against, or values to compare to
+func (c *checker) inferPattern(pattern ast.Expr, typ typing.Type, valid bool) {
+	if x, ok := pattern.(ast.Range); ok {
+		start, end := c.inferExpr(x.Start), c.inferExpr(x.End)
+		if !valid || !c.matches(start, typ, x.Start) || !c.matches(end, typ, x.End) {
+			return
+		}
+		if !c.compares(lexer.LessThanOrEqualTo, typ) || !c.compares(lexer.GreaterThanOrEqualTo, typ) {
+			Errors.Error(UndefinedComparison, "Cannot match "+typ.String()+" against a range, as it cannot be ordered", x.Loc())
+		}
+		return
+	}
+
+	if iden, ok := pattern.(ast.Identifier); ok {
+		if target, ok := c.classOf(typing.Type(iden.Name)); ok {
+			c.typ(pattern, typing.Type(target.Name))
+			if !valid {
+				return
+			}
+
+			class, ok := c.classOf(typ)
+			if !ok {
+				Errors.Error(NotAnObject, "Cannot match the class of "+typ.String()+", as it is not a class", pattern.Loc())
+			} else if !target.Is(class) && !class.Is(target) {
+				Errors.Error(MismatchedTypes, "An instance of "+class.Name+" can never be "+target.Name, pattern.Loc())
+			}
+			return
+		}
+	}
+
+	val := c.inferExpr(pattern)
+	if !valid || !c.matches(val, typ, pattern) {
+		return
+	}
+	if !c.compares(lexer.EqualTo, typ) {
+		Errors.Error(UndefinedComparison, "No comparison == exists for "+typ.String()+" and "+typ.String(), pattern.Loc())
+	}
+}
+
+// Literals are converted to the type matched against where possible, as with arguments
+func (c *checker) matches(val, typ typing.Type, src ast.Expr) bool {
+	if !c.valued(val, src) {
+		return false
+	}
+	if val == typ {
+		return true
+	}
+	if _, ok := c.AutoSingleInfer(val, typ, src); ok {
+		return true
+	}
+	Errors.Error(MismatchedTypes, "Expected "+typ.String()+", but got "+val.String()+" instead", src.Loc())
+	return false
+}
+
+func (c *checker) compares(comp lexer.TokenType, typ typing.Type) bool {
+	for i, sig := range c.program.Comparisons {
+		if sig.Comp == comp && sig.Left == typ && sig.Right == typ {
+			sig.Uses++
+			c.program.Comparisons[i] = sig
+			return true
+		}
+	}
+	return false
+}
+
+// Names the patterns known before running, so that the same one is not matched twice
+func constantPattern(pattern ast.Expr) (string, bool) {
+	switch x := pattern.(type) {
+	case ast.Integer:
+		return fmt.Sprint(x.Value), true
+	case ast.UnsignedInteger:
+		return fmt.Sprint(x.Value) + "u", true
+	case ast.Boolean:
+		return fmt.Sprint(x.Value), true
+	case ast.String:
+		return "\"" + x.Value + "\"", true
+	case ast.Access:
+		if iden, ok := x.Parent.(ast.Identifier); ok {
+			return iden.Name + "." + x.Child.Name, true
+		}
+	}
+	return "", false
+}
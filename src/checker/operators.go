@@ -0,0 +1,82 @@
+package checker
+
+import (
+	"sulfur/src/ast"
+	. "sulfur/src/errors"
+	"sulfur/src/lexer"
+	"sulfur/src/typing"
+	"sulfur/src/utils"
+)
+
+// Operators are always public, and only see the public members of their operands
+func (c *checker) inferOperation(x ast.Operation) {
+	operands := []typing.Type{}
+	for _, param := range x.Params {
+		if param.Referenced {
+			Errors.Error(MismatchedReference, "The parameters of an operator cannot be references", param.Loc())
+		}
+		operands = append(operands, typing.Type(param.Type.Name))
+	}
+
+	// An operation is written the same way for both orders of its operands, and cannot be defined twice
+	names := []string{}
+	builtin := false
+	switch len(operands) {
+	case 1:
+		if !utils.Contains(lexer.UnaryOperator, x.Op.Type) {
+			break
+		}
+
+		names = append(names, x.Op.Value+string(operands[0]))
+		for _, unop := range c.program.UnaryOps {
+			if unop.Module != "mod" && unop.Op == x.Op.Type && unop.Value == operands[0] {
+				builtin = true
+			}
+		}
+	case 2:
+		if !utils.Contains(lexer.BinaryOperator, x.Op.Type) {
+			break
+		}
+
+		left, right := operands[0], operands[1]
+		names = append(names, string(left)+" "+x.Op.Value+" "+string(right), string(right)+" "+x.Op.Value+" "+string(left))
+		for _, binop := range c.program.BinaryOps {
+			if binop.Module == "mod" || binop.Op != x.Op.Type {
+				continue
+			}
+			if binop.Left == left && binop.Right == right || binop.Left == right && binop.Right == left {
+				builtin = true
+			}
+		}
+	}
+
+	if builtin {
+		Errors.Error(AlreadyDefined, "The operation "+names[0]+" is already built in", x.Loc())
+	} else {
+		for _, name := range names {
+			if prev, ok := c.operators[name]; ok {
+				Errors.Error(AlreadyDefined, "The operation "+names[0]+" is already defined", x.Loc(), Note("previously defined here", prev))
+				break
+			}
+		}
+	}
+	for _, name := range names {
+		if _, ok := c.operators[name]; !ok {
+			c.operators[name] = x.Loc()
+		}
+	}
+
+	class := c.class
+	c.class = nil
+	c.inferFuncBody(x.Params, x.Return, x.FuncScope, x.Body)
+	c.class = class
+}
+
+// Finds the operation behind a compound assignment, which has to give back the type being assigned to
+func (c *checker) compound(op lexer.Token, typ, val typing.Type, src ast.Expr) bool {
+	ret, ok := c.binop(op.Type, typ, val)
+	if ok && ret != typ {
+		Errors.Error(MismatchedTypes, "Expected "+typ.String()+", but "+typ.String()+" "+op.Value+" "+val.String()+" gives "+ret.String(), src.Loc())
+	}
+	return ok
+}
@@ -10,20 +10,38 @@ import (
 
 func (c *checker) inferStmt(expr ast.Expr) {
 	switch x := expr.(type) {
+	case ast.NoExpr:
+		return
 	case ast.Declaration:
 		c.inferDeclaration(x)
+	case ast.Unpack:
+		c.inferUnpack(x)
 	case ast.Assignment:
 		c.inferAssignment(x)
 	case ast.IncDec:
 		c.inferIncDec(x)
 	case ast.Function:
 		c.inferFunction(x)
+	case ast.Class:
+		c.inferClass(x)
+	case ast.Operation:
+		c.inferOperation(x)
+	case ast.Enum:
+		c.inferEnum(x)
+	case ast.FieldAssignment:
+		c.inferFieldAssignment(x)
+	case ast.IndexAssignment:
+		c.inferIndexAssignment(x)
+	case ast.MethodCall:
+		c.inferMethodCall(x)
 	case ast.FuncCall:
 		c.inferFuncCall(x)
 	case ast.IfStatement:
 		c.inferIfStmt(x)
 	case ast.ForLoop:
 		c.inferForLoop(x)
+	case ast.ForEach:
+		c.inferForEach(x)
 	case ast.WhileLoop:
 		c.inferWhileLoop(x)
 	case ast.DoWhileLoop:
@@ -36,6 +54,16 @@ func (c *checker) inferStmt(expr ast.Expr) {
 		c.inferBreak(x)
 	case ast.Continue:
 		c.inferContinue(x)
+	case ast.Match:
+		c.inferMatch(x)
+	case ast.Fallthrough:
+		Errors.Error(InvalidFallthrough, "fallthrough can only end an arm of a match that has another arm after it", x.Loc())
+	case ast.Defer:
+		c.inferDefer(x)
+	case ast.Throw:
+		c.inferThrow(x)
+	case ast.Try:
+		c.inferTry(x, false)
 	default:
 		fmt.Println("Ignored type inferring statement")
 	}
@@ -46,75 +74,98 @@ func (c *checker) inferBlock(x ast.Block, header func()) {
 	if header != nil {
 		header()
 	}
-	for _, x := range x.Body {
-		c.inferStmt(x)
-	}
+	c.inferBody(x.Body)
+	c.unusedVars(x.Scope)
 	c.top = x.Scope.Parent
 }
 
 func (c *checker) inferDeclaration(x ast.Declaration) {
-	if _, ok := c.top.Vars[x.Name.Name]; ok {
-		Errors.Error(x.Name.Name+" is already defined", x.Name.Loc())
+	val := c.inferValue(x.Value)
+	if val == typing.Void {
+		Errors.Error(NoType, "Cannot declare a variable to have no type", x.Value.Loc())
+		val = typing.Invalid
+	} else if count := values(val); count > 1 {
+		Errors.Error(ValueCount, "Expected 1 value, but got "+amount(count)+" instead", x.Value.Loc())
+		val = typing.Invalid
 	}
 
-	val := c.inferExpr(x.Value)
-	if val == typing.Void {
-		Errors.Error("Cannot declare a variable to have no type", x.Value.Loc())
+	if !ast.Empty(x.Annotation) && !c.known(x.Annotation) {
+		val = typing.Invalid
+	} else if !ast.Empty(x.Annotation) && typing.Type(x.Annotation.Name) != val {
+		if _, ok := c.AutoUpcast(val, typing.Type(x.Annotation.Name), x.Value); !ok && val != typing.Invalid {
+			Errors.Error(MismatchedTypes, "Expected "+x.Annotation.Name+", but got "+val.String()+" instead", x.Value.Loc())
+		}
+		val = typing.Type(x.Annotation.Name)
 	}
 
-	if !ast.Empty(x.Annotation) && typing.Type(x.Annotation.Name) != val {
-		Errors.Error("Expected "+x.Annotation.Name+", but got "+val.String()+" instead", x.Value.Loc())
+	c.declare(x.Name, val, c.Refs.Has(x.Value))
+}
+
+// Declares a local variable in the current scope
+func (c *checker) declare(name ast.Identifier, typ typing.Type, references bool) {
+	if prev, ok := c.top.Vars[name.Name]; ok {
+		Errors.Error(AlreadyDefined, name.Name+" is already defined", name.Loc(), Note("previously defined here", prev.Pos))
+	} else if c.top.Parent != nil && !c.top.Seperate {
+		if prev, ok := c.top.Parent.Find(name.Name); ok {
+			Errors.Warn(Shadowing, name.Name+" shadows a variable in an outer scope", name.Loc(), Note("shadowed variable declared here", prev.Pos))
+		}
 	}
 
-	vari := ast.NewVariable(c.topfun, x.Name.Name, c.Refs.Has(x.Value), val, ast.Local)
-	c.top.Vars[x.Name.Name] = vari
+	vari := ast.NewVariable(c.topfun, name.Loc(), name.Name, references, typ, ast.Local)
+	c.top.Vars[name.Name] = vari
 	c.topfun.Decls[vari] = nil
 }
 
 func (c *checker) inferAssignment(x ast.Assignment) {
 	vari := c.top.Lookup(x.Name.Name, x.Name.Pos)
+	if vari.References {
+		vari.Uses++ // Writing through a reference is visible to the caller
+	}
 	if vari.Status == ast.Parameter && !vari.References {
-		Errors.Error("Illegal modification of a parameter", x.Value.Loc())
+		Errors.Error(ParameterMutation, "Illegal modification of a parameter", x.Value.Loc(), Note("declared here", vari.Pos))
+	}
+
+	val := c.inferValue(x.Value)
+	if !c.valued(val, x.Value) || vari.Type == typing.Invalid {
+		return
+	}
+
+	if !lexer.Empty(x.Op) && vari.Type != val && c.compound(x.Op, vari.Type, val, x.Value) {
+		return
 	}
 
-	val := c.inferExpr(x.Value)
 	if vari.Type != val {
 		conv, ok := c.AutoSingleInfer(val, vari.Type, x.Value)
 		if ok {
 			val, _ = AutoSwitch(val, vari.Type, conv)
 		} else {
-			Errors.Error("Expected "+vari.Type.String()+", but got "+val.String()+" instead", x.Value.Loc())
+			Errors.Error(MismatchedTypes, "Expected "+vari.Type.String()+", but got "+val.String()+" instead", x.Value.Loc(), Note("declared as "+vari.Type.String()+" here", vari.Pos))
+			return
 		}
 	}
 
 	if !vari.References && c.Refs.Has(x.Value) {
 		typ := vari.Type.String()
-		Errors.Error("Expected "+typ+", but got &"+typ+" instead", x.Value.Loc())
+		Errors.Error(MismatchedReference, "Expected "+typ+", but got &"+typ+" instead", x.Value.Loc(), Note("declared here", vari.Pos))
 	}
 
-	if lexer.Empty(x.Op) {
+	if lexer.Empty(x.Op) || c.compound(x.Op, vari.Type, val, x.Value) {
 		return
 	}
 
-	for i, binop := range c.program.BinaryOps {
-		if binop.Op != x.Op.Type {
-			continue
-		}
-
-		if binop.Left == vari.Type && binop.Right == val {
-			binop.Uses++
-			c.program.BinaryOps[i] = binop
-			return
-		}
-	}
-
-	Errors.Error("No operation "+x.Op.Value+" exists for "+vari.Type.String()+" and "+val.String(), x.Op.Location)
+	Errors.Error(UndefinedOperation, "No operation "+x.Op.Value+" exists for "+vari.Type.String()+" and "+val.String(), x.Op.Location)
 }
 
 func (c *checker) inferIncDec(x ast.IncDec) {
 	vari := c.top.Lookup(x.Name.Name, x.Name.Pos)
-	if vari.Status == ast.Parameter && !vari.Referenced {
-		Errors.Error("Illegal modification of a parameter", x.Name.Loc())
+	if vari.Type == typing.Invalid {
+		return
+	}
+	if vari.References {
+		vari.Uses++
+	}
+	if vari.Status == ast.Parameter && !vari.References {
+		Errors.Error(ParameterMutation, "Illegal modification of a parameter", x.Loc(), Note("declared here", vari.Pos))
 	}
 
 	for _, id := range c.program.IncDecs {
@@ -127,33 +178,60 @@ func (c *checker) inferIncDec(x ast.IncDec) {
 		}
 	}
 
-	Errors.Error("No operation "+x.Op.Value+" exists for "+vari.Type.String(), x.Op.Location)
+	Errors.Error(UndefinedOperation, "No operation "+x.Op.Value+" exists for "+vari.Type.String(), x.Op.Location)
 }
 
 func (c *checker) inferFunction(x ast.Function) {
-	c.topfun = x.FuncScope
-	c.inferBlock(x.Body, func() {
-		for _, param := range x.Params {
-			if param.Referenced {
-				c.program.References.Add(typing.Type(param.Type.Name))
-			}
+	c.funcs = append(c.funcs, x)
+
+	// Functions inside of methods cannot see the current instance
+	class := c.class
+	c.class = nil
+	c.inferThrows(x)
+	c.inferFuncBody(x.Params, x.Return, x.FuncScope, x.Body)
+	c.class = class
+}
 
-			c.top.Vars[param.Name.Name] = ast.NewVariable(
-				c.topfun,
-				param.Name.Name,
-				param.Referenced,
-				typing.Type(param.Type.Name),
-				ast.Parameter,
-			)
-		}
+func (c *checker) inferFuncBody(params []ast.Param, ret []ast.Identifier, fnscope *ast.FuncScope, body ast.Block) {
+	for _, typ := range ret {
+		c.known(typ)
+	}
+
+	c.topfun = fnscope
+	c.inferBlock(body, func() {
+		c.declareParams(params)
 	})
 	c.topfun = c.topfun.Parent
 }
 
+func (c *checker) declareParams(params []ast.Param) {
+	for _, param := range params {
+		typ := typing.Type(param.Type.Name)
+		if !c.known(param.Type) {
+			typ = typing.Invalid
+		} else if param.Referenced {
+			if c.referable(typ, param.Loc()) {
+				c.program.References.Add(typ)
+			} else {
+				typ = typing.Invalid
+			}
+		}
+
+		c.top.Vars[param.Name.Name] = ast.NewVariable(
+			c.topfun,
+			param.Name.Loc(),
+			param.Name.Name,
+			param.Referenced,
+			typ,
+			ast.Parameter,
+		)
+	}
+}
+
 func (c *checker) inferIfStmt(x ast.IfStatement) {
 	cond := c.inferExpr(x.Cond)
-	if cond != typing.Boolean {
-		Errors.Error("Expected "+typing.Boolean+", but got "+cond.String()+" instead", x.Cond.Loc())
+	if cond != typing.Boolean && cond != typing.Invalid {
+		Errors.Error(MismatchedTypes, "Expected "+typing.Boolean+", but got "+cond.String()+" instead", x.Cond.Loc())
 	}
 
 	c.inferBlock(x.Body, nil)
@@ -168,19 +246,45 @@ func (c *checker) inferForLoop(x ast.ForLoop) {
 	c.inferBlock(x.Body, func() {
 		c.inferStmt(x.Init)
 		cond := c.inferExpr(x.Cond)
-		if cond != typing.Boolean {
-			Errors.Error("Expected "+typing.Boolean+", but got "+cond.String()+" instead", x.Cond.Loc())
+		if cond != typing.Boolean && cond != typing.Invalid {
+			Errors.Error(MismatchedTypes, "Expected "+typing.Boolean+", but got "+cond.String()+" instead", x.Cond.Loc())
 		}
 		c.inferStmt(x.Inc)
 	})
 }
 
+// The item is declared in the body, and takes on every item of an array or list in order, or every key of a map
+func (c *checker) inferForEach(x ast.ForEach) {
+	x.Body.Scope.Loop = true
+
+	iter := c.inferExpr(x.Iterable)
+	item, ok := iter.Item()
+	if !ok {
+		item, ok = iter.ListItem()
+	}
+	if !ok {
+		item, _, ok = iter.Entry()
+	}
+	if !ok && c.valued(iter, x.Iterable) {
+		Errors.Error(NotAnArray, "Cannot iterate over "+iter.String()+", as it is not an array, list or map", x.Iterable.Loc())
+	}
+	if !ok {
+		item = typing.Invalid
+	}
+
+	c.inferBlock(x.Body, func() {
+		vari := ast.NewVariable(c.topfun, x.Item.Loc(), x.Item.Name, false, item, ast.Local)
+		c.top.Vars[x.Item.Name] = vari
+		c.topfun.Decls[vari] = nil
+	})
+}
+
 func (c *checker) inferWhileLoop(x ast.WhileLoop) {
 	x.Body.Scope.Loop = true
 
 	cond := c.inferExpr(x.Cond)
-	if cond != typing.Boolean {
-		Errors.Error("Expected "+typing.Boolean+", but got "+cond.String()+" instead", x.Cond.Loc())
+	if cond != typing.Boolean && cond != typing.Invalid {
+		Errors.Error(MismatchedTypes, "Expected "+typing.Boolean+", but got "+cond.String()+" instead", x.Cond.Loc())
 	}
 
 	c.inferBlock(x.Body, nil)
@@ -190,8 +294,8 @@ func (c *checker) inferDoWhileLoop(x ast.DoWhileLoop) {
 	x.Body.Scope.Loop = true
 
 	cond := c.inferExpr(x.Cond)
-	if cond != typing.Boolean {
-		Errors.Error("Expected "+typing.Boolean+", but got "+cond.String()+" instead", x.Cond.Loc())
+	if cond != typing.Boolean && cond != typing.Invalid {
+		Errors.Error(MismatchedTypes, "Expected "+typing.Boolean+", but got "+cond.String()+" instead", x.Cond.Loc())
 	}
 
 	c.inferBlock(x.Body, nil)
@@ -204,29 +308,60 @@ func (c *checker) inferLoop(x ast.Loop) {
 
 func (c *checker) inferReturn(x ast.Return) {
 	if c.topfun.Parent == nil {
-		Errors.Error("Cannot use a return statement outside of a function", x.Pos)
+		Errors.Error(ReturnOutsideFunction, "Cannot use a return statement outside of a function", x.Pos)
 	}
 	var val typing.Type
 	if ast.Empty(x.Value) {
 		val = typing.Void
 	} else {
-		val = c.inferExpr(x.Value)
+		val = c.inferValue(x.Value)
 	}
 
 	ret := c.topfun.Return
-	if ret != val {
-		Errors.Error("Expected "+ret.String()+", but got "+val.String()+" instead", x.Loc())
+	if ret == val || val == typing.Invalid || ret == typing.Invalid {
+		return
+	}
+	if want, have := values(ret), values(val); want != have && (want > 1 || have > 1) {
+		Errors.Error(ValueCount, "Expected "+amount(want)+", but got "+amount(have)+" instead", x.Loc())
+		return
+	}
+
+	// Values returned together are upcast one by one
+	if tuple, ok := x.Value.(ast.Tuple); ok {
+		rets, _ := ret.Elems()
+		vals, _ := val.Elems()
+		for i, item := range *tuple.Items {
+			if _, ok := c.AutoUpcast(vals[i], rets[i], item); !ok && vals[i] != rets[i] {
+				Errors.Error(MismatchedTypes, "Expected "+rets[i].String()+", but got "+vals[i].String()+" instead", item.Loc())
+			}
+		}
+		return
+	}
+
+	if _, ok := c.AutoUpcast(val, ret, x.Value); ok {
+		return
 	}
+	Errors.Error(MismatchedTypes, "Expected "+ret.String()+", but got "+val.String()+" instead", x.Loc())
 }
 
 func (c *checker) inferBreak(x ast.Break) {
 	if !c.top.InLoop() {
-		Errors.Error("Can only use a break statement inside a loop", x.Loc())
+		Errors.Error(JumpOutsideLoop, "Can only use a break statement inside a loop", x.Loc())
 	}
 }
 
 func (c *checker) inferContinue(x ast.Continue) {
 	if !c.top.InLoop() {
-		Errors.Error("Can only use a continue statement inside a loop", x.Loc())
+		Errors.Error(JumpOutsideLoop, "Can only use a continue statement inside a loop", x.Loc())
+	}
+}
+
+// Deferred statements run while their scope is being left, so they cannot leave it themselves
+func (c *checker) inferDefer(x ast.Defer) {
+	switch x.Value.(type) {
+	case ast.FuncCall, ast.MethodCall, ast.Assignment, ast.FieldAssignment, ast.IndexAssignment, ast.IncDec:
+		c.inferStmt(x.Value)
+	default:
+		Errors.Error(InvalidDefer, "Only calls and assignments can be deferred", x.Value.Loc())
 	}
 }
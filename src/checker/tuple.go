@@ -0,0 +1,67 @@
+package checker
+
+import (
+	"fmt"
+	"sulfur/src/ast"
+	. "sulfur/src/errors"
+	"sulfur/src/typing"
+)
+
+// Counts the values held by a type, where only tuples hold more than one
+func values(typ typing.Type) int {
+	if elems, ok := typ.Elems(); ok {
+		return len(elems)
+	}
+	if typ == typing.Void {
+		return 0
+	}
+	return 1
+}
+
+func amount(count int) string {
+	if count == 1 {
+		return "1 value"
+	}
+	return fmt.Sprint(count) + " values"
+}
+
+func (c *checker) inferTuple(x ast.Tuple) typing.Type {
+	elems := []typing.Type{}
+	valid := true
+	for _, item := range *x.Items {
+		typ := c.inferExpr(item)
+		if values(typ) > 1 {
+			Errors.Error(ValueCount, "Expected 1 value, but got "+amount(values(typ))+" instead", item.Loc())
+			typ = typing.Invalid
+		}
+		valid = c.valued(typ, item) && valid
+		elems = append(elems, typ)
+	}
+
+	if !valid {
+		return c.typ(x, typing.Invalid)
+	}
+	return c.typ(x, typing.Tuple(elems...))
+}
+
+// Every value is declared as its own variable, so there have to be exactly as many names as values
+func (c *checker) inferUnpack(x ast.Unpack) {
+	val := c.inferExpr(x.Value)
+	elems, _ := val.Elems()
+	if val == typing.Void {
+		Errors.Error(NoType, "Cannot declare a variable to have no type", x.Value.Loc())
+	} else if val != typing.Invalid && len(elems) != len(x.Names) {
+		Errors.Error(ValueCount, "Expected "+amount(len(x.Names))+", but got "+amount(values(val))+" instead", x.Value.Loc())
+	}
+	if len(elems) != len(x.Names) {
+		elems = nil
+	}
+
+	for i, name := range x.Names {
+		typ := typing.Type(typing.Invalid)
+		if elems != nil {
+			typ = elems[i]
+		}
+		c.declare(name, typ, false)
+	}
+}
@@ -0,0 +1,49 @@
+package checker
+
+import (
+	"strings"
+	"sulfur/src/ast"
+	. "sulfur/src/errors"
+)
+
+func (c *checker) inferBody(body []ast.Expr) {
+	var exit ast.Expr
+	for _, x := range body {
+		if exit != nil {
+			Errors.Warn(Unreachable, "Unreachable code", x.Loc(), Note("control flow leaves here", exit.Loc()))
+			exit = nil
+		}
+
+		c.inferStmt(x)
+		switch x.(type) {
+		case ast.Return, ast.Break, ast.Continue:
+			exit = x
+		}
+	}
+}
+
+// Variables starting with an underscore are allowed to go unused
+func (c *checker) unusedVars(scope *ast.Scope) {
+	for name, vari := range scope.Vars {
+		if vari.Uses > 0 || strings.HasPrefix(name, "_") {
+			continue
+		}
+
+		if vari.Status == ast.Parameter {
+			Errors.Warn(UnusedParameter, "The parameter "+name+" is never used", vari.Pos)
+		} else {
+			Errors.Warn(UnusedVariable, "The variable "+name+" is never used", vari.Pos)
+		}
+	}
+}
+
+func (c *checker) unusedFuncs() {
+	for _, x := range c.funcs {
+		for _, fun := range c.program.Functions {
+			if fun.Module == "mod" && fun.Name == x.Name.Name && fun.Uses == 0 {
+				Errors.Warn(UnusedFunction, "The function "+fun.Name+" is never used", x.Name.Loc())
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package compiler
+
+import (
+	"sulfur/src/ast"
+	"sulfur/src/lexer"
+	"sulfur/src/typing"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// Like strings, arrays are a length and an address, and own their memory along with any strings or arrays in them
+func (g *generator) arrayType(typ typing.Type) types.Type {
+	if arr, ok := g.arrays[typ]; ok {
+		return arr
+	}
+
+	item, _ := typ.Item()
+	arr := g.mod.NewTypeDef("type."+string(typ), types.NewStruct(
+		types.I32,                       // length
+		types.NewPointer(g.lltyp(item)), // address
+	))
+	g.arrays[typ] = arr
+
+	g.genArrayCopy(typ)
+	g.genArrayFree(typ)
+	return arr
+}
+
+// Whether values of the type are freed when they go out of scope
+func (g *generator) owned(typ typing.Type) bool {
+	_, isArray := typ.Item()
+	_, isList := typ.ListItem()
+	_, _, isMap := typ.Entry()
+	_, _, isFunc := typ.Signature()
+	_, isObject := g.builtins.classes[string(typ)]
+	return isArray || isList || isMap || isFunc || isObject || typ == typing.String
+}
+
+// Values read out of a field or container are copied, so that replacing them there leaves what was read intact
+func (g *generator) detach(val value.Value, typ typing.Type) value.Value {
+	if _, isObject := g.builtins.classes[string(typ)]; isObject || !g.owned(typ) {
+		return val
+	}
+	copied := g.bl.NewCall(g.copys[typ], val)
+	g.top.Strings[copied] = typ
+	return copied
+}
+
+func (g *generator) genArrayCopy(typ typing.Type) {
+	item, _ := typ.Item()
+	lltyp, llitem := g.arrays[typ], g.lltyp(item)
+	fun := g.mod.NewFunc(".copy:"+typ.String(), lltyp, ir.NewParam("", lltyp))
+	g.copys[typ] = fun
+
+	entry := fun.NewBlock("entry")
+	length := entry.NewExtractValue(fun.Params[0], 0)
+	src := entry.NewExtractValue(fun.Params[0], 1)
+	size := entry.NewMul(length, constant.NewInt(types.I32, int64(g.size(item))))
+	mem := entry.NewCall(g.intrinsics["malloc"], size)
+	dst := entry.NewBitCast(mem, types.NewPointer(llitem))
+
+	exit := g.eachItem(fun, entry, length, func(bl *ir.Block, i value.Value) {
+		from := bl.NewGetElementPtr(llitem, src, i)
+		var val value.Value = bl.NewLoad(llitem, from)
+		if g.owned(item) {
+			val = bl.NewCall(g.copys[item], val)
+		}
+		to := bl.NewGetElementPtr(llitem, dst, i)
+		bl.NewStore(val, to)
+	})
+
+	arr := exit.NewInsertValue(constant.NewUndef(lltyp.(*types.StructType)), length, 0)
+	exit.NewRet(exit.NewInsertValue(arr, dst, 1))
+}
+
+func (g *generator) genArrayFree(typ typing.Type) {
+	item, _ := typ.Item()
+	lltyp, llitem := g.arrays[typ], g.lltyp(item)
+	fun := g.mod.NewFunc(".free:"+typ.String(), types.Void, ir.NewParam("", lltyp))
+	g.autofrees[typ] = fun
+
+	entry := fun.NewBlock("entry")
+	length := entry.NewExtractValue(fun.Params[0], 0)
+	addr := entry.NewExtractValue(fun.Params[0], 1)
+
+	exit := entry
+	if g.owned(item) {
+		exit = g.eachItem(fun, entry, length, func(bl *ir.Block, i value.Value) {
+			ptr := bl.NewGetElementPtr(llitem, addr, i)
+			bl.NewCall(g.autofrees[item], bl.NewLoad(llitem, ptr))
+		})
+	}
+
+	mem := exit.NewBitCast(addr, types.I8Ptr)
+	exit.NewCall(g.intrinsics["free"], mem)
+	exit.NewRet(nil)
+}
+
+// Builds a loop over every position of an array, returning the block after it
+func (g *generator) eachItem(fun *ir.Func, entry *ir.Block, length value.Value, body func(bl *ir.Block, i value.Value)) *ir.Block {
+	cond := fun.NewBlock("")
+	loop := fun.NewBlock("")
+	exit := fun.NewBlock("")
+	entry.NewBr(cond)
+
+	i := cond.NewPhi(ir.NewIncoming(Zero, entry))
+	cond.NewCondBr(cond.NewICmp(enum.IPredSLT, i, length), loop, exit)
+
+	body(loop, i)
+	next := loop.NewAdd(i, One)
+	i.Incs = append(i.Incs, ir.NewIncoming(next, loop))
+	loop.NewBr(cond)
+
+	return exit
+}
+
+func (g *generator) genArray(x ast.Array) value.Value {
+	bl := g.bl
+	item := typing.Type(x.Type.Name)
+	typ := typing.Array(item)
+	lltyp, llitem := g.lltyp(typ), g.lltyp(item)
+
+	length := constant.NewInt(types.I32, int64(len(*x.Items)))
+	size := constant.NewInt(types.I32, int64(len(*x.Items)*g.size(item)))
+	mem := bl.NewCall(g.intrinsics["malloc"], size)
+	addr := bl.NewBitCast(mem, types.NewPointer(llitem))
+
+	for i, expr := range *x.Items {
+		val := g.genExpr(expr)
+		if g.owned(item) {
+			val = g.bl.NewCall(g.copys[item], val)
+		}
+
+		ptr := g.bl.NewGetElementPtr(llitem, addr, constant.NewInt(types.I32, int64(i)))
+		store := g.bl.NewStore(val, ptr)
+		store.Align = g.align(item)
+	}
+
+	arr := g.genBasicStruct(lltyp, length, addr)
+	g.top.Strings[arr] = typ
+	return arr
+}
+
+func (g *generator) genItemPtr(x ast.Index) value.Value {
+	arr := g.genHeld(x.Array)
+	idx := g.genExpr(x.Index)
+
+	if _, ok := g.Types[x.Array].ListItem(); ok {
+		g.genBounds(idx, g.genListLength(arr))
+		return g.genListItemPtr(arr, idx, g.Types[x])
+	}
+
+	bl := g.bl
+	g.genBounds(idx, bl.NewExtractValue(arr, 0))
+	addr := bl.NewExtractValue(arr, 1)
+	return bl.NewGetElementPtr(g.lltyp(g.Types[x]), addr, idx)
+}
+
+// The runtime aborts when the index is out of range, which keeps the branch out of the expression
+func (g *generator) genBounds(idx, length value.Value) {
+	check := g.bl.NewCall(g.intrinsics[".bounds"], idx, length)
+	check.CallingConv = enum.CallingConvFast
+}
+
+func (g *generator) genIndex(x ast.Index) value.Value {
+	return g.detach(g.genHeldIndex(x), g.Types[x])
+}
+
+func (g *generator) genHeldIndex(x ast.Index) value.Value {
+	if _, _, ok := g.Types[x.Array].Entry(); ok {
+		return g.genMapGet(g.genHeld(x.Array), g.genExpr(x.Index), g.Types[x])
+	}
+
+	ptr := g.genItemPtr(x)
+	load := g.bl.NewLoad(g.lltyp(g.Types[x]), ptr)
+	load.Align = g.align(g.Types[x])
+	return load
+}
+
+func (g *generator) genIndexAssignment(x ast.IndexAssignment) {
+	if _, _, ok := g.Types[x.Index.Array].Entry(); ok {
+		g.genMapAssignment(x)
+		return
+	}
+
+	val := g.genExpr(x.Value)
+	typ := g.operand(x.Value)
+	item := g.Types[x.Index]
+	ptr := g.genItemPtr(x.Index)
+
+	bl := g.bl
+	old := bl.NewLoad(g.lltyp(item), ptr)
+	old.Align = g.align(item)
+
+	if !lexer.Empty(x.Op) {
+		val = g.genBinaryOperation(old, val, x.Op.Type, item, typ, item)
+	}
+
+	// Arrays and lists own their items, so the new value is copied and the old one freed
+	if g.owned(item) {
+		val = bl.NewCall(g.copys[item], val)
+		bl.NewCall(g.autofrees[item], old)
+	}
+
+	store := bl.NewStore(val, ptr)
+	store.Align = g.align(item)
+}
+
+func (g *generator) genLength(x ast.Access) value.Value {
+	return g.bl.NewExtractValue(g.genHeld(x.Parent), 0)
+}
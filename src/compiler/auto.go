@@ -11,7 +11,7 @@ func (g *generator) autoCast(val value.Value, expr ast.Expr, step string) value.
 	if conv, ok := g.AutoConvs[expr]; ok {
 		new := g.genBasicTypeConv(val, conv.From, conv.To)
 		if new == Zero {
-			Errors.Error("Unexpected generating error during "+step+" creation", expr.Loc())
+			Errors.Fatal(Internal, "Unexpected generating error during "+step+" creation", expr.Loc())
 		}
 
 		return new
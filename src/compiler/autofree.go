@@ -13,6 +13,9 @@ func (g *generator) autoFree() {
 		free := g.autofrees[typ]
 		bl.NewCall(free, cmplx)
 	}
+	if bl.Term == nil {
+		g.destroy(g.top)
+	}
 }
 
 func (g *generator) genHiddens() {
@@ -68,6 +68,9 @@ func (g *generator) genBasicDecl(name string, typ types.Type, val value.Value, l
 		bundle := g.refs[vari.Type]
 
 		call := bl.NewCall(bundle.newref, val)
+		if vari.Captured {
+			bl.NewCall(bundle.ref, call)
+		}
 		store := bl.NewStore(call, alloca)
 		store.Align = 8
 
@@ -200,6 +203,9 @@ func (g *generator) genBasicUnaryOp(val value.Value, op lexer.TokenType, typ typ
 
 func (g *generator) genBasicComparison(left, right value.Value, comp lexer.TokenType, typ typing.Type) value.Value {
 	bl := g.bl
+	if _, ok := g.builtins.enums[string(typ)]; ok {
+		typ = typing.Integer
+	}
 	switch comp {
 	case lexer.LessThan:
 		switch typ {
@@ -258,14 +264,23 @@ func (g *generator) genBasicComparison(left, right value.Value, comp lexer.Token
 
 func (g *generator) genBasicTypeConv(val value.Value, from, to typing.Type) value.Value {
 	bl := g.bl
+
 	conv := g.srcConv(string(from), string(to))
+	if conv != nil && conv.Module == "mod" {
+		return g.genResult(bl.NewCall(conv.Ir, val), conv.To)
+	}
+
+	// Instances are converted to the classes they extend by reinterpreting them, as they share the same beginning
+	if _, ok := g.builtins.classes[string(from)]; ok {
+		return bl.NewBitCast(val, g.lltyp(to))
+	}
 
 	if from == to {
 		return val
 	}
 
 	if conv.Complex {
-		return bl.NewCall(conv.Ir, val)
+		return g.genResult(bl.NewCall(conv.Ir, val), conv.To)
 	} else {
 		typ := g.lltyp(conv.To)
 
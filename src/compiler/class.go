@@ -0,0 +1,539 @@
+package compiler
+
+import (
+	"sulfur/src/ast"
+	"sulfur/src/builtins"
+	"sulfur/src/lexer"
+	"sulfur/src/location"
+	"sulfur/src/typing"
+
+	. "sulfur/src/errors"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// The type of the first slot of every vtable, which destroys instances of its class
+var destroyer = types.NewPointer(types.NewFunc(types.Void, types.I8Ptr))
+
+func (g *generator) genClasses() {
+	mod := g.mod
+
+	// Classes are registered before their fields are filled in, so that they can contain each other
+	for i, class := range g.program.Classes {
+		class.Ir = mod.NewTypeDef("class."+class.Name, types.NewStruct())
+
+		g.program.Classes[i] = class
+		g.builtins.classes[class.Name] = &g.program.Classes[i]
+	}
+
+	for i := range g.program.Classes {
+		class := &g.program.Classes[i]
+		ptr := types.NewPointer(class.Ir)
+
+		// Every instance starts with a pointer to the vtable of its class and how many references it has, followed by the fields of its ancestors
+		fields := []types.Type{types.I8Ptr, types.I32}
+		for _, field := range class.AllFields() {
+			fields = append(fields, g.lltyp(field.Type))
+		}
+		class.Ir.(*types.StructType).Fields = fields
+
+		typ := typing.Type(class.Name)
+		g.copys[typ] = mod.NewFunc(".copy:"+class.Name, ptr, ir.NewParam("", ptr))
+		g.autofrees[typ] = mod.NewFunc(".free:"+class.Name, types.Void, ir.NewParam("", ptr))
+
+		for j, method := range class.Methods {
+			if method.Uses == 0 {
+				continue
+			}
+
+			params := []*ir.Param{ir.NewParam("self", ptr)}
+			for k, param := range method.Params {
+				var p *ir.Param
+				if param.Referenced {
+					p = ir.NewParam("", g.refs[param.Type].ptr)
+				} else {
+					p = ir.NewParam("", g.lltyp(param.Type))
+				}
+				method.Params[k].Ir = p
+				params = append(params, p)
+			}
+
+			method.Ir = mod.NewFunc(
+				class.Module+"."+class.Name+"."+method.Name,
+				g.lltyp(method.Return),
+				params...,
+			)
+			method.Ir.CallingConv = enum.CallingConvFast
+			method.Ir.Linkage = enum.LinkagePrivate
+
+			class.Methods[j] = method
+		}
+	}
+
+	for i := range g.program.Classes {
+		class := &g.program.Classes[i]
+		g.genDestroy(class)
+		g.genVtable(class)
+		g.genCount(class)
+	}
+}
+
+// The type of a vtable slot, where the instance is passed as the class that first declared the method
+func (g *generator) slotType(class *builtins.ClassSignature, name string) types.Type {
+	var root *builtins.ClassSignature
+	for parent := class; parent != nil; parent = parent.Parent {
+		if _, ok := parent.Own(name); ok {
+			root = parent
+		}
+	}
+	method, _ := root.Own(name)
+
+	params := []types.Type{types.NewPointer(root.Ir)}
+	for _, param := range method.Params {
+		if param.Referenced {
+			params = append(params, g.refs[param.Type].ptr)
+		} else {
+			params = append(params, g.lltyp(param.Type))
+		}
+	}
+	return types.NewPointer(types.NewFunc(g.lltyp(method.Return), params...))
+}
+
+// Methods are called through the vtable, so that a class extending another can override them
+func (g *generator) genVtable(class *builtins.ClassSignature) {
+	// The first slot destroys the instance, so that it is destroyed as the class it was created as
+	slots := []types.Type{destroyer}
+	impls := []constant.Constant{constant.NewBitCast(class.Free, destroyer)}
+	for _, name := range class.Slots() {
+		typ := g.slotType(class, name)
+		slots = append(slots, typ)
+
+		method, _ := class.Method(name)
+		if method.Ir == nil {
+			impls = append(impls, constant.NewNull(typ.(*types.PointerType)))
+		} else {
+			impls = append(impls, constant.NewBitCast(method.Ir, typ))
+		}
+	}
+
+	typ := g.mod.NewTypeDef("vtable."+class.Name, types.NewStruct(slots...))
+	vtable := g.mod.NewGlobalDef("vtable."+class.Name, constant.NewStruct(typ.(*types.StructType), impls...))
+	vtable.Linkage = enum.LinkagePrivate
+	vtable.Immutable = true
+
+	class.Vtable = vtable
+}
+
+// Destroys an instance by calling its destructor, letting go of what its fields own and then freeing the instance itself
+func (g *generator) genDestroy(class *builtins.ClassSignature) {
+	fun := g.mod.NewFunc(".destroy:"+class.Name, types.Void, ir.NewParam("", types.NewPointer(class.Ir)))
+	fun.CallingConv = enum.CallingConvFast
+	fun.Linkage = enum.LinkagePrivate
+	obj := fun.Params[0]
+
+	destroy := fun.NewBlock("entry")
+
+	// Destructors run from the class itself up to its oldest ancestor
+	for parent := class; parent != nil; parent = parent.Parent {
+		if del, ok := parent.Own("del"); ok && del.Ir != nil {
+			destroy.NewCall(del.Ir, destroy.NewBitCast(obj, del.Ir.Params[0].Typ))
+		}
+	}
+	for i, field := range class.AllFields() {
+		if !g.owned(field.Type) {
+			continue
+		}
+
+		fieldptr := destroy.NewGetElementPtr(class.Ir, obj, Zero, constant.NewInt(types.I32, int64(i+2)))
+		fieldptr.InBounds = true
+		load := destroy.NewLoad(g.lltyp(field.Type), fieldptr)
+		load.Align = 8
+		destroy.NewCall(g.autofrees[field.Type], load)
+	}
+	mem := destroy.NewBitCast(obj, types.I8Ptr)
+	destroy.NewCall(g.intrinsics["free"], mem)
+	destroy.NewRet(nil)
+
+	class.Free = fun
+}
+
+// Instances are shared rather than copied, and destroyed through their vtable once the last reference to them is let go of
+func (g *generator) genCount(class *builtins.ClassSignature) {
+	typ := typing.Type(class.Name)
+	ptr := types.NewPointer(class.Ir)
+
+	cp := g.copys[typ]
+	cp.CallingConv = enum.CallingConvFast
+	cp.Linkage = enum.LinkagePrivate
+	entry := cp.NewBlock("entry")
+	ref := cp.NewBlock("ref")
+	exit := cp.NewBlock("exit")
+	entry.NewCondBr(entry.NewICmp(enum.IPredEQ, cp.Params[0], constant.NewNull(ptr)), exit, ref)
+	count := g.countPtr(ref, class, cp.Params[0])
+	ref.NewStore(ref.NewAdd(ref.NewLoad(types.I32, count), One), count)
+	ref.NewBr(exit)
+	exit.NewRet(cp.Params[0])
+
+	free := g.autofrees[typ]
+	free.CallingConv = enum.CallingConvFast
+	free.Linkage = enum.LinkagePrivate
+	obj := free.Params[0]
+	entry = free.NewBlock("entry")
+	deref := free.NewBlock("deref")
+	last := free.NewBlock("last")
+	exit = free.NewBlock("exit")
+	entry.NewCondBr(entry.NewICmp(enum.IPredEQ, obj, constant.NewNull(ptr)), exit, deref)
+
+	count = g.countPtr(deref, class, obj)
+	left := deref.NewSub(deref.NewLoad(types.I32, count), One)
+	deref.NewStore(left, count)
+	deref.NewCondBr(deref.NewICmp(enum.IPredEQ, left, Zero), last, exit)
+
+	// Whatever the destructors do with the instance cannot bring it back to no references and destroy it twice
+	last.NewStore(One, count)
+
+	vtableptr := last.NewGetElementPtr(class.Ir, obj, Zero, Zero)
+	vtableptr.InBounds = true
+	vtable := last.NewLoad(types.I8Ptr, vtableptr)
+	vtable.Align = 8
+	fn := last.NewLoad(destroyer, last.NewBitCast(vtable, types.NewPointer(destroyer)))
+	fn.Align = 8
+	call := last.NewCall(fn, last.NewBitCast(obj, types.I8Ptr))
+	call.CallingConv = enum.CallingConvFast
+	last.NewBr(exit)
+	exit.NewRet(nil)
+}
+
+func (g *generator) countPtr(bl *ir.Block, class *builtins.ClassSignature, obj value.Value) value.Value {
+	ptr := bl.NewGetElementPtr(class.Ir, obj, Zero, One)
+	ptr.InBounds = true
+	return ptr
+}
+
+func (g *generator) genClass(x ast.Class) {
+	class := g.builtins.classes[x.Name.Name]
+
+	gen := func(name string, params []ast.Param, fnscope *ast.FuncScope, body ast.Block) {
+		method, ok := class.Method(name)
+		if !ok || method.Ir == nil {
+			return
+		}
+
+		args := []value.Value{}
+		for _, param := range method.Params {
+			args = append(args, param.Ir)
+		}
+
+		self, prev := g.ctx.self, g.ctx.class
+		g.ctx.self, g.ctx.class = method.Ir.Params[0], class
+		g.genFuncBody(method.Ir, method.Return, params, args, fnscope, body)
+		g.ctx.self, g.ctx.class = self, prev
+	}
+
+	for _, op := range x.Operations {
+		g.genOperation(op)
+	}
+	for _, method := range x.Methods {
+		gen(method.Name.Name, method.Params, method.FuncScope, method.Body)
+	}
+	for _, newdel := range []ast.NewDel{x.New, x.Del} {
+		if !ast.Empty(newdel) {
+			gen(newdel.Name(), newdel.Params, newdel.FuncScope, newdel.Body)
+		}
+	}
+
+	for _, to := range x.Conversions {
+		conv := g.srcConv(x.Name.Name, to.Type.Name)
+		if conv == nil || conv.Ir == nil {
+			continue
+		}
+
+		self, prev := g.ctx.self, g.ctx.class
+		g.ctx.self, g.ctx.class = conv.Ir.Params[0], class
+		g.genFuncBody(conv.Ir, conv.To, nil, nil, to.FuncScope, to.Body)
+		g.ctx.self, g.ctx.class = self, prev
+	}
+}
+
+func (g *generator) genNew(x ast.New) value.Value {
+	bl := g.bl
+	class := g.builtins.classes[x.Class.Name]
+	ptr := types.NewPointer(class.Ir)
+
+	// The size of a class is the address of the second instance in an array starting at null
+	end := constant.NewGetElementPtr(class.Ir, constant.NewNull(ptr), One)
+	size := constant.NewPtrToInt(end, types.I32)
+
+	mem := bl.NewCall(g.intrinsics["malloc"], size)
+	obj := bl.NewBitCast(mem, ptr)
+	store := bl.NewStore(constant.NewZeroInitializer(class.Ir), obj)
+	store.Align = 8
+
+	vtableptr := bl.NewGetElementPtr(class.Ir, obj, Zero, Zero)
+	vtableptr.InBounds = true
+	vtable := bl.NewStore(constant.NewBitCast(class.Vtable, types.I8Ptr), vtableptr)
+	vtable.Align = 8
+	bl.NewStore(One, g.countPtr(bl, class, obj))
+
+	if _, ctor, ok := class.Constructor(); ok {
+		self := bl.NewBitCast(obj, ctor.Ir.Params[0].Typ)
+		params := g.genArgs(ctor.Params, *x.Params)
+		bl.NewCall(ctor.Ir, append([]value.Value{self}, params...)...)
+	}
+
+	g.top.Objects = append(g.top.Objects, ast.Object{
+		Value: obj,
+		Type:  typing.Type(class.Name),
+	})
+
+	return obj
+}
+
+// Finds the instance a member is accessed on, which is the current instance if there is no parent
+func (g *generator) genParent(x ast.Access) (value.Value, *builtins.ClassSignature) {
+	if ast.Empty(x.Parent) {
+		return g.ctx.self, g.ctx.class
+	}
+	if g.super(x.Parent) {
+		parent := g.ctx.class.Parent
+		return g.bl.NewBitCast(g.ctx.self, types.NewPointer(parent.Ir)), parent
+	}
+
+	obj := g.genExpr(x.Parent)
+	class, ok := g.builtins.classes[string(g.Types[x.Parent])]
+	if !ok {
+		Errors.Fatal(Internal, "Cannot access "+x.Child.Name+" on something that is not an object", x.Loc())
+	}
+	return obj, class
+}
+
+func (g *generator) genFieldPtr(x ast.Access) (value.Value, *builtins.FieldSignature) {
+	obj, class := g.genParent(x)
+	idx, field, ok := class.Field(x.Child.Name)
+	if !ok {
+		Errors.Fatal(Internal, class.Name+" has no field "+x.Child.Name, x.Loc())
+	}
+
+	ptr := g.bl.NewGetElementPtr(class.Ir, obj, Zero, constant.NewInt(types.I32, int64(idx+2)))
+	ptr.InBounds = true
+	return ptr, field
+}
+
+func (g *generator) genAccess(x ast.Access) value.Value {
+	return g.detach(g.genHeldAccess(x), g.Types[x])
+}
+
+func (g *generator) genHeldAccess(x ast.Access) value.Value {
+	if g.isEnumAccess(x) {
+		return g.genEnumAccess(x)
+	}
+	if _, ok := g.Types[x.Parent].Item(); ok {
+		return g.genLength(x)
+	}
+	if _, ok := g.Types[x.Parent].ListItem(); ok {
+		return g.genListLength(g.genHeld(x.Parent))
+	}
+	if _, _, ok := g.Types[x.Parent].Entry(); ok {
+		return g.genMapLength(g.genHeld(x.Parent))
+	}
+	if x.Child.Name == "self" && (ast.Empty(x.Parent) || g.super(x.Parent)) {
+		obj, class := g.genParent(x)
+		if _, _, ok := class.Field("self"); !ok {
+			return obj
+		}
+	}
+
+	ptr, field := g.genFieldPtr(x)
+	load := g.bl.NewLoad(g.lltyp(field.Type), ptr)
+	load.Align = g.align(field.Type)
+	return load
+}
+
+func (g *generator) genFieldAssignment(x ast.FieldAssignment) {
+	val := g.genExpr(x.Value)
+	typ := g.operand(x.Value)
+	ptr, field := g.genFieldPtr(x.Field)
+
+	bl := g.bl
+	old := bl.NewLoad(g.lltyp(field.Type), ptr)
+	old.Align = g.align(field.Type)
+
+	if !lexer.Empty(x.Op) {
+		val = g.genBinaryOperation(old, val, x.Op.Type, field.Type, typ, field.Type)
+	}
+
+	// Fields own their strings and arrays, so the new value is copied and the old one freed
+	if g.owned(field.Type) {
+		val = bl.NewCall(g.copys[field.Type], val)
+		bl.NewCall(g.autofrees[field.Type], old)
+	}
+
+	store := bl.NewStore(val, ptr)
+	store.Align = g.align(field.Type)
+}
+
+func (g *generator) super(expr ast.Expr) bool {
+	iden, ok := expr.(ast.Identifier)
+	return ok && iden.Name == "super" && g.ctx.class != nil
+}
+
+func (g *generator) genMethodCall(x ast.MethodCall) value.Value {
+	if typ := g.Types[x.Method.Parent]; !ast.Empty(x.Method.Parent) {
+		if _, ok := typ.ListItem(); ok {
+			return g.genListMethod(x, typ)
+		}
+		if _, _, ok := typ.Entry(); ok {
+			return g.genMapMethod(x, typ)
+		}
+	}
+
+	bl := g.bl
+	name := x.Method.Child.Name
+	obj, class := g.genParent(x.Method)
+
+	super := g.super(x.Method.Parent)
+	method, ok := class.Method(name)
+	if super && name == "new" {
+		_, method, ok = class.Constructor()
+	}
+
+	var sigs []builtins.ParamSignature
+	if ok {
+		sigs = method.Params
+	}
+	params := g.genArgs(sigs, *x.Params)
+
+	// Calls through super skip the vtable, as they always mean the parent's method
+	if super {
+		if !ok || method.Ir == nil {
+			Errors.Fatal(Internal, "The method "+name+" of "+class.Name+" is undefined", x.Loc())
+		}
+
+		self := bl.NewBitCast(obj, method.Ir.Params[0].Typ)
+		return g.genResult(bl.NewCall(method.Ir, append([]value.Value{self}, params...)...), method.Return)
+	}
+
+	slot := -1
+	for i, slotname := range class.Slots() {
+		if slotname == name {
+			slot = i
+		}
+	}
+	if slot == -1 {
+		Errors.Fatal(Internal, "The method "+name+" of "+class.Name+" is undefined", x.Loc())
+	}
+
+	vtabletyp := class.Vtable.ContentType
+	vtableptr := bl.NewGetElementPtr(class.Ir, obj, Zero, Zero)
+	vtableptr.InBounds = true
+	raw := bl.NewLoad(types.I8Ptr, vtableptr)
+	raw.Align = 8
+	vtable := bl.NewBitCast(raw, types.NewPointer(vtabletyp))
+
+	typ := g.slotType(class, name)
+	fnptr := bl.NewGetElementPtr(vtabletyp, vtable, Zero, constant.NewInt(types.I32, int64(slot+1)))
+	fnptr.InBounds = true
+	fn := bl.NewLoad(typ, fnptr)
+	fn.Align = 8
+
+	self := bl.NewBitCast(obj, typ.(*types.PointerType).ElemType.(*types.FuncType).Params[0])
+	return g.genResult(bl.NewCall(fn, append([]value.Value{self}, params...)...), method.Return)
+}
+
+// Tests whether the class of an instance is the given class, or any class extending it
+func (g *generator) genIs(x ast.Comparison) value.Value {
+	return g.genBasicIs(g.genExpr(x.Left), g.Types[x.Left], g.Types[x.Right])
+}
+
+func (g *generator) genBasicIs(obj value.Value, typ, to typing.Type) value.Value {
+	bl := g.bl
+	target := g.builtins.classes[string(to)]
+
+	vtableptr := bl.NewGetElementPtr(g.builtins.classes[string(typ)].Ir, obj, Zero, Zero)
+	vtableptr.InBounds = true
+	vtable := bl.NewLoad(types.I8Ptr, vtableptr)
+	vtable.Align = 8
+
+	var is value.Value = constant.False
+	for i := range g.program.Classes {
+		class := &g.program.Classes[i]
+		if !class.Is(target) {
+			continue
+		}
+
+		eq := bl.NewICmp(enum.IPredEQ, vtable, constant.NewBitCast(class.Vtable, types.I8Ptr))
+		is = bl.NewOr(is, eq)
+	}
+	return is
+}
+
+// Lets go of the instances held by the variables of a scope and the ones it created or was returned, newest first
+func (g *generator) destroy(scope *ast.Scope) {
+	bl := g.bl
+	for i := len(scope.Owners) - 1; i >= 0; i-- {
+		vari := scope.Owners[i]
+		bl.NewCall(g.autofrees[vari.Type], g.genBasicIden(vari))
+	}
+	for i := len(scope.Objects) - 1; i >= 0; i-- {
+		obj := scope.Objects[i]
+		bl.NewCall(g.autofrees[obj.Type], obj.Value)
+	}
+}
+
+// Calls hand the instances they return over to the current scope
+func (g *generator) genResult(val value.Value, typ typing.Type) value.Value {
+	if _, ok := g.builtins.classes[string(typ)]; ok {
+		g.top.Objects = append(g.top.Objects, ast.Object{
+			Value: val,
+			Type:  typ,
+		})
+	} else if elems, ok := typ.Elems(); ok {
+		for i, elem := range elems {
+			if _, ok := g.builtins.classes[string(elem)]; ok {
+				g.top.Objects = append(g.top.Objects, ast.Object{
+					Value: g.bl.NewExtractValue(val, uint64(i)),
+					Type:  elem,
+				})
+			}
+		}
+	}
+	return val
+}
+
+// Returned instances get a reference of their own, as the scopes being left let go of theirs
+func (g *generator) genHandover(val value.Value, typ typing.Type) {
+	if _, ok := g.builtins.classes[string(typ)]; ok {
+		g.bl.NewCall(g.copys[typ], val)
+	} else if elems, ok := typ.Elems(); ok {
+		for i, elem := range elems {
+			if _, ok := g.builtins.classes[string(elem)]; ok {
+				g.bl.NewCall(g.copys[elem], g.bl.NewExtractValue(val, uint64(i)))
+			}
+		}
+	}
+}
+
+// Variables hold a reference to their instance until the scope they were declared in is left
+func (g *generator) hold(name string, loc *location.Location) {
+	vari := g.top.Lookup(name, loc)
+	if _, ok := g.builtins.classes[string(vari.Type)]; !ok || vari.References {
+		return
+	}
+	g.bl.NewCall(g.copys[vari.Type], g.genBasicIden(vari))
+	g.top.Owners = append(g.top.Owners, vari)
+}
+
+// Runs the deferred statements and lets go of the instances of every scope being left, up to and including the first one that stops it
+func (g *generator) destroyUntil(stop func(scope *ast.Scope) bool) {
+	for scope := g.top; scope != nil; scope = scope.Parent {
+		g.genDefers(scope)
+		g.destroy(scope)
+		if stop(scope) {
+			break
+		}
+	}
+}
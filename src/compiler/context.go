@@ -1,6 +1,7 @@
 package compiler
 
 import (
+	"sulfur/src/builtins"
 	"sulfur/src/utils"
 
 	"github.com/llir/llvm/ir"
@@ -14,4 +15,6 @@ type context struct {
 	complex    bool
 	exits      utils.Stack[*ir.Block]
 	blockcount int
+	self       value.Value
+	class      *builtins.ClassSignature
 }
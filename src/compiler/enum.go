@@ -0,0 +1,150 @@
+package compiler
+
+import (
+	"sulfur/src/ast"
+	"sulfur/src/builtins"
+	"sulfur/src/lexer"
+	"sulfur/src/typing"
+	"unicode/utf8"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+func (g *generator) genEnums() {
+	for i, enum := range g.program.Enums {
+		g.builtins.enums[enum.Name] = &g.program.Enums[i]
+	}
+}
+
+// Elements are stored as their position in the enum
+func (g *generator) genEnumAccess(x ast.Access) value.Value {
+	enum := g.builtins.enums[x.Parent.(ast.Identifier).Name]
+	for i, elem := range enum.Elems {
+		if elem == x.Child.Name {
+			return constant.NewInt(types.I32, int64(i))
+		}
+	}
+	return Zero
+}
+
+func (g *generator) isEnumAccess(x ast.Access) bool {
+	iden, ok := x.Parent.(ast.Identifier)
+	if !ok {
+		return false
+	}
+	_, ok = g.builtins.enums[iden.Name]
+	return ok
+}
+
+// Fills in the conversions between an enum and the type of its values
+func (g *generator) genEnum(x ast.Enum) {
+	enum := g.builtins.enums[x.Name.Name]
+	numbered := true
+	for _, val := range x.Values {
+		if !ast.Empty(val) {
+			numbered = false
+		}
+	}
+
+	bl := g.bl
+	defer func() { g.bl = bl }()
+
+	if conv := g.srcConv(enum.Name, string(enum.Type)); conv != nil && conv.Ir != nil {
+		g.bl = conv.Ir.NewBlock("")
+		var val value.Value = conv.Ir.Params[0]
+		if !numbered {
+			// Positions that are not in the enum give the first value
+			val = g.enumValue(enum.Type, x.Values[0], 0)
+			for i := 1; i < len(x.Values); i++ {
+				cmp := g.genBasicComparison(conv.Ir.Params[0], constant.NewInt(types.I32, int64(i)), lexer.EqualTo, typing.Integer)
+				val = g.bl.NewSelect(cmp, g.enumValue(enum.Type, x.Values[i], i), val)
+			}
+		}
+		if enum.Type == typing.String {
+			val = g.bl.NewCall(g.copys[typing.String], val)
+		}
+		g.bl.NewRet(val)
+	}
+
+	if conv := g.srcConv(string(enum.Type), enum.Name); conv != nil && conv.Ir != nil {
+		g.bl = conv.Ir.NewBlock("")
+		var val value.Value = conv.Ir.Params[0]
+		if !numbered {
+			// Values that are not in the enum give no element
+			val = NegOne
+			for i := len(x.Values) - 1; i >= 0; i-- {
+				cmp := g.genEnumEqual(conv.Ir.Params[0], g.enumValue(enum.Type, x.Values[i], i), enum.Type)
+				val = g.bl.NewSelect(cmp, constant.NewInt(types.I32, int64(i)), val)
+			}
+		}
+		g.bl.NewRet(g.genElement(val, enum))
+	}
+}
+
+// Strings are compared the way map keys are, as they have no comparison of their own
+func (g *generator) genEnumEqual(val, elem value.Value, typ typing.Type) value.Value {
+	if typ != typing.String {
+		return g.genBasicComparison(val, elem, lexer.EqualTo, typ)
+	}
+	if g.hashmap == nil {
+		g.genMapRuntime()
+	}
+	eq := g.bl.NewCall(g.intrinsics[".equal:string"], g.keyPtr(g.bl, val), g.keyPtr(g.bl, elem))
+	eq.CallingConv = enum.CallingConvFast
+	return eq
+}
+
+// The runtime aborts when the value is not one of the enum, so that every element is one of its positions
+func (g *generator) genElement(idx value.Value, sig *builtins.EnumSignature) value.Value {
+	name := constant.NewCharArrayFromString(sig.Name + "\x00")
+	glob := g.mod.NewGlobalDef(".enum."+sig.Name, name)
+	glob.Linkage = enum.LinkagePrivate
+	glob.UnnamedAddr = enum.UnnamedAddrUnnamedAddr
+	glob.Immutable = true
+
+	str := constant.NewGetElementPtr(name.Typ, glob, Zero, Zero)
+	str.InBounds = true
+	elem := g.bl.NewCall(g.intrinsics[".element"], idx, constant.NewInt(types.I32, int64(len(sig.Elems))), str)
+	elem.CallingConv = enum.CallingConvFast
+	return elem
+}
+
+// The value of an element as a constant, where elements without a value are numbered by their position
+func (g *generator) enumValue(typ typing.Type, val ast.Expr, i int) constant.Constant {
+	switch x := val.(type) {
+	case ast.NoExpr:
+		return constant.NewInt(types.I32, int64(i))
+	case ast.Integer:
+		if typ == typing.Float {
+			return constant.NewFloat(types.Float, float64(x.Value))
+		}
+		return constant.NewInt(types.I32, x.Value)
+	case ast.UnsignedInteger:
+		return constant.NewInt(types.I32, int64(x.Value))
+	case ast.Float:
+		return constant.NewFloat(types.Float, x.Value)
+	case ast.Boolean:
+		return constant.NewBool(x.Value)
+	case ast.String:
+		glob := g.strs[x.Value]
+		str := constant.NewGetElementPtr(glob.typ, glob.glob, Zero, Zero)
+		str.InBounds = true
+		return constant.NewStruct(
+			g.str.(*types.StructType),
+			constant.NewInt(types.I32, int64(utf8.RuneCountInString(x.Value))),
+			str,
+		)
+	case ast.UnaryOp:
+		switch neg := g.enumValue(typ, x.Value, i).(type) {
+		case *constant.Int:
+			return constant.NewInt(types.I32, -neg.X.Int64())
+		case *constant.Float:
+			f, _ := neg.X.Float64()
+			return constant.NewFloat(types.Float, -f)
+		}
+	}
+	return Zero
+}
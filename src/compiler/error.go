@@ -0,0 +1,72 @@
+package compiler
+
+import (
+	"sulfur/src/ast"
+	"sulfur/src/typing"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// Functions that throw take a pointer to where the error goes first, which holds NegOne unless something was thrown
+func (g *generator) genThrow(err value.Value) {
+	g.bl.NewStore(err, g.ctx.fun.Params[0])
+	g.destroyUntil(func(scope *ast.Scope) bool { return scope.Seperate })
+
+	g.breaks[g.bl] = true
+	g.bl.NewBr(g.ctx.exits.Final())
+}
+
+// The value of a call that threw is never used, as the try either catches the error or leaves the function
+func (g *generator) genTry(x ast.Try) value.Value {
+	top := g.ctx.fun
+	id := g.id()
+	call := x.Value.(ast.FuncCall)
+	fun := g.builtins.funcs[call.Func.Name]
+
+	// The error lives in the entry block, so that loops do not grow the stack
+	slot := top.Blocks[0].NewAlloca(types.I32)
+	slot.LocalName = ".err" + id
+	g.bl.NewStore(NegOne, slot)
+
+	args := append([]value.Value{slot}, g.genArgs(fun.Params, *call.Params)...)
+	val := g.bl.NewCall(fun.Ir, args...)
+	err := g.bl.NewLoad(types.I32, slot)
+
+	failBl := top.NewBlock("try.fail" + id)
+	endBl := top.NewBlock("try.end" + id)
+	g.bl.NewCondBr(g.bl.NewICmp(enum.IPredNE, err, NegOne), failBl, endBl)
+	from := g.bl
+
+	g.bl = failBl
+	switch {
+	case x.Arms != nil:
+		g.genArms(err, fun.Throws, *x.Arms)
+		g.bl.NewBr(endBl)
+	case !ast.Empty(x.Catch):
+		// Like the value of the call, the value caught with is left to whoever takes it, and anything else made is freed here
+		strings, objects, owners := g.top.Strings, g.top.Objects, g.top.Owners
+		g.top.Strings, g.top.Objects, g.top.Owners = map[value.Value]typing.Type{}, []ast.Object{}, []*ast.Variable{}
+		other := g.genExpr(x.Catch)
+		delete(g.top.Strings, other)
+		for val, typ := range g.top.Strings {
+			g.bl.NewCall(g.autofrees[typ], val)
+		}
+		g.genHandover(other, fun.Return)
+		g.destroy(g.top)
+		g.top.Strings, g.top.Objects, g.top.Owners = strings, objects, owners
+
+		caught := g.bl
+		caught.NewBr(endBl)
+
+		g.bl = endBl
+		return g.genResult(endBl.NewPhi(ir.NewIncoming(val, from), ir.NewIncoming(other, caught)), fun.Return)
+	default:
+		g.genThrow(err)
+	}
+
+	g.bl = endBl
+	return g.genResult(val, fun.Return)
+}
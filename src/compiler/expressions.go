@@ -2,6 +2,8 @@ package compiler
 
 import (
 	"sulfur/src/ast"
+	"sulfur/src/builtins"
+	"sulfur/src/lexer"
 	"sulfur/src/typing"
 	"unicode/utf8"
 
@@ -38,14 +40,48 @@ func (g *generator) genExpr(expr ast.Expr) value.Value {
 		return g.autoCast(g.genFuncCall(x), x, "function call")
 	case ast.Reference:
 		return g.genReference(x)
+	case ast.New:
+		return g.autoCast(g.genNew(x), x, "instance")
+	case ast.Access:
+		return g.autoCast(g.genAccess(x), x, "field access")
+	case ast.Array:
+		return g.autoCast(g.genArray(x), x, "array")
+	case ast.Index:
+		return g.autoCast(g.genIndex(x), x, "index")
+	case ast.List:
+		return g.autoCast(g.genList(x), x, "list")
+	case ast.Map:
+		return g.autoCast(g.genMap(x), x, "map")
+	case ast.Tuple:
+		return g.genTuple(x)
+	case ast.MethodCall:
+		return g.autoCast(g.genMethodCall(x), x, "method call")
+	case ast.Lambda:
+		return g.genLambda(x)
+	case ast.Try:
+		return g.autoCast(g.genTry(x), x, "try")
 	}
 
-	Errors.Error("Expression cannot be generated", expr.Loc())
+	Errors.Fatal(Internal, "Expression cannot be generated", expr.Loc())
 	return constant.NewInt(types.I32, 0)
 }
 
+// Containers that are only looked into or changed in place are not copied out of what holds them
+func (g *generator) genHeld(expr ast.Expr) value.Value {
+	switch x := expr.(type) {
+	case ast.Access:
+		return g.genHeldAccess(x)
+	case ast.Index:
+		return g.genHeldIndex(x)
+	}
+	return g.genExpr(expr)
+}
+
 func (g *generator) genIdentifier(x ast.Identifier) value.Value {
-	vari := g.top.Lookup(x.Name, x.Pos)
+	vari, ok := g.top.Find(x.Name)
+	if !ok {
+		return g.genFuncValue(x)
+	}
 	return g.genBasicIden(vari)
 }
 
@@ -77,27 +113,31 @@ func (g *generator) genString(x ast.String) value.Value {
 }
 
 func (g *generator) genBinaryOp(x ast.BinaryOp) value.Value {
-	val := g.genBasicBinaryOp(g.genExpr(x.Left), g.genExpr(x.Right), x.Op.Type, g.Types[x])
+	val := g.genBinaryOperation(g.genExpr(x.Left), g.genExpr(x.Right), x.Op.Type, g.operand(x.Left), g.operand(x.Right), g.Types[x])
 	if val == Zero {
-		Errors.Error("Unexpected generating error during binary operation", x.Op.Location)
+		Errors.Fatal(Internal, "Unexpected generating error during binary operation", x.Op.Location)
 	}
 
 	return val
 }
 
 func (g *generator) genUnaryOp(x ast.UnaryOp) value.Value {
-	val := g.genBasicUnaryOp(g.genExpr(x.Value), x.Op.Type, g.Types[x])
+	val := g.genUnaryOperation(g.genExpr(x.Value), x.Op.Type, g.operand(x.Value), g.Types[x])
 	if val == Zero {
-		Errors.Error("Unexpected generating error during unary operation", x.Op.Location)
+		Errors.Fatal(Internal, "Unexpected generating error during unary operation", x.Op.Location)
 	}
 
 	return val
 }
 
 func (g *generator) genComparison(x ast.Comparison) value.Value {
+	if x.Comp.Type == lexer.Is {
+		return g.genIs(x)
+	}
+
 	val := g.genBasicComparison(g.genExpr(x.Left), g.genExpr(x.Right), x.Comp.Type, g.Types[x.Left])
 	if val == Zero {
-		Errors.Error("Unexpected generating error during comparison", x.Comp.Location)
+		Errors.Fatal(Internal, "Unexpected generating error during comparison", x.Comp.Location)
 	}
 
 	return val
@@ -106,7 +146,7 @@ func (g *generator) genComparison(x ast.Comparison) value.Value {
 func (g *generator) genTypeConv(x ast.TypeConv) value.Value {
 	conv := g.genBasicTypeConv(g.genExpr(x.Value), g.Types[x.Value], g.Types[x])
 	if conv == Zero {
-		Errors.Error("Unexpected generating error during type conversion", x.Loc())
+		Errors.Fatal(Internal, "Unexpected generating error during type conversion", x.Loc())
 	}
 
 	return conv
@@ -118,24 +158,66 @@ func (g *generator) genFuncCall(x ast.FuncCall) value.Value {
 
 	for _, fun := range g.program.Functions {
 		if fun.Name == x.Func.Name {
-			params := []value.Value{}
-			for _, param := range *x.Params {
-				params = append(params, g.genExpr(param))
-			}
-
-			return bl.NewCall(fun.Ir, params...)
+			params := g.genArgs(fun.Params, *x.Params)
+			return g.genResult(bl.NewCall(fun.Ir, params...), fun.Return)
 		}
 	}
+	if vari, ok := g.top.Find(x.Func.Name); ok {
+		return g.genValueCall(vari, x)
+	}
 
-	Errors.Error("The function "+x.Func.Name+" is undefined", x.Func.Pos)
+	Errors.Fatal(Internal, "The function "+x.Func.Name+" is undefined", x.Func.Pos)
 	return nil
 }
 
+// Arguments left over for a variadic parameter are collected into an array on the stack, which only borrows them
+func (g *generator) genArgs(sigs []builtins.ParamSignature, params []ast.Expr) []value.Value {
+	args := []value.Value{}
+	if len(sigs) == 0 || !sigs[len(sigs)-1].Variadic {
+		for _, param := range params {
+			args = append(args, g.genExpr(param))
+		}
+		return args
+	}
+
+	fixed := len(sigs) - 1
+	for _, param := range params[:fixed] {
+		args = append(args, g.genExpr(param))
+	}
+
+	rest := params[fixed:]
+	if len(rest) == 1 {
+		if spread, ok := rest[0].(ast.Spread); ok {
+			return append(args, g.genExpr(spread.Value))
+		}
+	}
+
+	item, _ := sigs[fixed].Type.Item()
+	llitem := g.lltyp(item)
+	vals := []value.Value{}
+	for _, param := range rest {
+		vals = append(vals, g.genExpr(param))
+	}
+
+	bl := g.bl
+	mem := g.ctx.fun.Blocks[0].NewAlloca(types.NewArray(uint64(len(vals)), llitem))
+	addr := bl.NewBitCast(mem, types.NewPointer(llitem))
+	for i, val := range vals {
+		ptr := bl.NewGetElementPtr(llitem, addr, constant.NewInt(types.I32, int64(i)))
+		store := bl.NewStore(val, ptr)
+		store.Align = g.align(item)
+	}
+
+	length := constant.NewInt(types.I32, int64(len(vals)))
+	arr := bl.NewInsertValue(constant.NewUndef(g.lltyp(sigs[fixed].Type).(*types.StructType)), length, 0)
+	return append(args, bl.NewInsertValue(arr, addr, 1))
+}
+
 func (g *generator) genReference(x ast.Reference) value.Value {
 	bl := g.bl
 	vari := g.top.Lookup(x.Variable.Name, x.Variable.Loc())
 	if !vari.Referenced {
-		Errors.Error(vari.Name+" is never referenced", x.Variable.Loc())
+		Errors.Fatal(Internal, vari.Name+" is never referenced", x.Variable.Loc())
 	}
 
 	bundle := g.refs[vari.Type]
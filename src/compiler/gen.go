@@ -4,6 +4,7 @@ import (
 	"sulfur/src/ast"
 	"sulfur/src/builtins"
 	"sulfur/src/checker"
+	"sulfur/src/target"
 	"sulfur/src/typing"
 	"sulfur/src/utils"
 
@@ -21,6 +22,11 @@ type generator struct {
 	bl         *ir.Block // TODO: Move bl to context
 	breaks     map[*ir.Block]bool
 	str        types.Type
+	arrays     map[typing.Type]types.Type
+	list       types.Type
+	hashmap    types.Type
+	fn         types.Type
+	env        types.Type
 	refs       map[typing.Type]ref_bundle
 	strs       map[string]StringGlobal
 	builtins   llvm_builtins
@@ -29,9 +35,11 @@ type generator struct {
 	intrinsics map[string]*ir.Func
 }
 
-func Generate(program *ast.Program, props *checker.VariableProperties, path string) string {
+func Generate(program *ast.Program, props *checker.VariableProperties, path string, tgt target.Target) string {
 	mod := ir.NewModule()
 	mod.SourceFilename = path
+	mod.TargetTriple = tgt.Triple()
+	mod.DataLayout = tgt.DataLayout()
 
 	str := mod.NewTypeDef("type.string", types.NewStruct(
 		types.I32,    // length
@@ -56,17 +64,25 @@ func Generate(program *ast.Program, props *checker.VariableProperties, path stri
 			false,
 			stack,
 			0,
+			nil,
+			nil,
 		},
 		program.Contents.Scope,
 		program.FuncScope,
 		bl,
 		make(map[*ir.Block]bool),
 		str,
+		make(map[typing.Type]types.Type),
+		nil,
+		nil,
+		nil,
+		nil,
 		make(map[typing.Type]ref_bundle),
 		make(map[string]StringGlobal),
 		llvm_builtins{
 			make(map[string]*builtins.FuncSignature),
 			make(map[string]*builtins.ClassSignature),
+			make(map[string]*builtins.EnumSignature),
 			make(map[string]*builtins.BinaryOpSignature),
 			make(map[string]*builtins.UnaryOpSignature),
 			make(map[string]*builtins.IncDecSignature),
@@ -78,25 +94,27 @@ func Generate(program *ast.Program, props *checker.VariableProperties, path stri
 		make(map[string]*ir.Func),
 	}
 
+	g.genIntrinsics()
+	g.genHiddens()
 	g.genStrings()
+	g.genEnums()
+	g.genClasses()
 	g.genReferences()
 	g.genFuncs()
-	g.genClasses()
 	g.genBinOps()
 	g.genUnOps()
 	g.genIncDecs()
 	g.genComps()
 	g.genTypeConvs()
-	g.genIntrinsics()
-	g.genHiddens()
 
 	g.genAllocas(g.topfun)
 
 	for _, x := range program.Contents.Body {
 		g.genStmt(x)
 	}
-	g.leaveRefs()
+	g.genDefers(g.top)
 	g.autoFree()
+	g.leaveRefs()
 
 	g.exit()
 	exit.NewRet(Zero)
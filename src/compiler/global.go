@@ -2,6 +2,7 @@ package compiler
 
 import (
 	"fmt"
+	"sulfur/src/typing"
 
 	"github.com/llir/llvm/ir"
 	"github.com/llir/llvm/ir/constant"
@@ -83,6 +84,11 @@ func (g *generator) genReferences() {
 			ref,
 			deref,
 		}
+		switch typ {
+		case typing.Integer, typing.Unsigned, typing.Float, typing.Boolean:
+		default:
+			g.genRefFuncs(typ, g.refs[typ])
+		}
 	}
 }
 
@@ -95,6 +101,9 @@ func (g *generator) genFuncs() {
 		name := fun.Module + "." + fun.Name
 
 		params := []*ir.Param{}
+		if fun.Throws != "" {
+			params = append(params, ir.NewParam(".err", types.NewPointer(types.I32)))
+		}
 		for i, param := range fun.Params {
 			if param.Referenced {
 				p := ir.NewParam("", g.refs[param.Type].ptr)
@@ -119,21 +128,6 @@ func (g *generator) genFuncs() {
 	}
 }
 
-func (g *generator) genClasses() {
-	mod := g.mod
-	for i, class := range g.program.Classes {
-		typs := []types.Type{}
-		for _, field := range class.Fields {
-			typs = append(typs, g.lltyp(field.Type))
-		}
-
-		class.Ir = mod.NewTypeDef("class."+class.Name, types.NewStruct(typs...))
-
-		g.program.Classes[i] = class
-		g.builtins.classes[class.Name] = &g.program.Classes[i]
-	}
-}
-
 func (g *generator) genBinOps() {
 	for i, binop := range g.program.BinaryOps {
 		if binop.Uses == 0 {
@@ -141,7 +135,9 @@ func (g *generator) genBinOps() {
 		}
 
 		name := binop.Module + "." + binop.Op.OperatorName() + ":" + binop.Left.String() + "_" + binop.Right.String()
-		if g.complex(binop.Return) {
+		if binop.Swapped {
+			binop.Complex = true
+		} else if binop.Module == "mod" || g.complex(binop.Return) {
 			binop.Ir = g.mod.NewFunc(
 				name,
 				g.lltyp(binop.Return),
@@ -165,7 +161,7 @@ func (g *generator) genUnOps() {
 		}
 
 		name := unop.Module + "." + unop.Op.OperatorName() + ":" + unop.Value.String()
-		if g.complex(unop.Return) {
+		if unop.Module == "mod" || g.complex(unop.Return) {
 			unop.Ir = g.mod.NewFunc(
 				name,
 				g.lltyp(unop.Return),
@@ -224,7 +220,7 @@ func (g *generator) genTypeConvs() {
 		}
 
 		name := conv.Module + ".conv:" + string(conv.From) + "_" + string(conv.To)
-		if g.complex(conv.To) {
+		if conv.Module == "mod" || g.complex(conv.To) {
 			conv.Ir = g.mod.NewFunc(
 				name,
 				g.lltyp(conv.To),
@@ -14,4 +14,12 @@ func (g *generator) genIntrinsics() {
 
 	g.intrinsics["clz"] = mod.NewFunc("llvm.ctlz.i32", types.I32, ir.NewParam("", types.I32), poison)
 	g.intrinsics["ctz"] = mod.NewFunc("llvm.cttz.i32", types.I32, ir.NewParam("", types.I32), poison)
+
+	g.intrinsics["malloc"] = mod.NewFunc("malloc", types.I8Ptr, ir.NewParam("", types.I32))
+	g.intrinsics["free"] = mod.NewFunc("free", types.Void, ir.NewParam("", types.I8Ptr))
+
+	g.intrinsics[".bounds"] = mod.NewFunc(".bounds", types.Void, ir.NewParam("", types.I32), ir.NewParam("", types.I32))
+	g.intrinsics[".bounds"].CallingConv = enum.CallingConvFast
+	g.intrinsics[".element"] = mod.NewFunc(".element", types.I32, ir.NewParam("", types.I32), ir.NewParam("", types.I32), ir.NewParam("", types.I8Ptr))
+	g.intrinsics[".element"].CallingConv = enum.CallingConvFast
 }
@@ -0,0 +1,250 @@
+package compiler
+
+import (
+	"fmt"
+	"sulfur/src/ast"
+	"sulfur/src/builtins"
+	. "sulfur/src/errors"
+	"sulfur/src/typing"
+	"sulfur/src/utils"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// Every function value is the same struct, so they all share one copy and free
+func (g *generator) funcType(typ typing.Type) types.Type {
+	if g.fn == nil {
+		g.genFuncRuntime()
+	}
+	g.copys[typ] = g.intrinsics[".copy:func"]
+	g.autofrees[typ] = g.intrinsics[".free:func"]
+	return g.fn
+}
+
+func (g *generator) genFuncRuntime() {
+	mod := g.mod
+	g.fn = mod.NewTypeDef("type.func", types.NewStruct(
+		types.I8Ptr, // code
+		types.I8Ptr, // environment, null if nothing is captured
+	))
+
+	// Environments start with their count and a function dropping the boxes they hold
+	drop := types.NewPointer(types.NewFunc(types.Void, types.I8Ptr))
+	g.env = mod.NewTypeDef("type.env", types.NewStruct(
+		types.I32, // count
+		drop,      // drop
+	))
+
+	cp := mod.NewFunc(".copy:func", g.fn, ir.NewParam("", g.fn))
+	entry := cp.NewBlock("entry")
+	count := cp.NewBlock("count")
+	exit := cp.NewBlock("exit")
+	env := g.envOf(entry, cp.Params[0])
+	entry.NewCondBr(entry.NewICmp(enum.IPredEQ, env, constant.NewNull(types.NewPointer(g.env))), exit, count)
+
+	ptr := g.envField(count, env, 0)
+	count.NewStore(count.NewAdd(count.NewLoad(types.I32, ptr), One), ptr)
+	count.NewBr(exit)
+	exit.NewRet(cp.Params[0])
+
+	free := mod.NewFunc(".free:func", types.Void, ir.NewParam("", g.fn))
+	entry = free.NewBlock("entry")
+	count = free.NewBlock("count")
+	last := free.NewBlock("last")
+	exit = free.NewBlock("exit")
+	env = g.envOf(entry, free.Params[0])
+	entry.NewCondBr(entry.NewICmp(enum.IPredEQ, env, constant.NewNull(types.NewPointer(g.env))), exit, count)
+
+	ptr = g.envField(count, env, 0)
+	left := count.NewSub(count.NewLoad(types.I32, ptr), One)
+	count.NewStore(left, ptr)
+	count.NewCondBr(count.NewICmp(enum.IPredEQ, left, Zero), last, exit)
+
+	dropper := last.NewLoad(drop, g.envField(last, env, 1))
+	last.NewCall(dropper, last.NewExtractValue(free.Params[0], 1))
+	last.NewBr(exit)
+	exit.NewRet(nil)
+
+	g.intrinsics[".copy:func"] = cp
+	g.intrinsics[".free:func"] = free
+}
+
+func (g *generator) envOf(bl *ir.Block, fn value.Value) value.Value {
+	return bl.NewBitCast(bl.NewExtractValue(fn, 1), types.NewPointer(g.env))
+}
+
+func (g *generator) envField(bl *ir.Block, env value.Value, field int64) value.Value {
+	ptr := bl.NewGetElementPtr(g.env, env, Zero, constant.NewInt(types.I32, field))
+	ptr.InBounds = true
+	return ptr
+}
+
+// Lambdas take their environment as the first parameter, where the boxes of what they capture are kept
+func (g *generator) genLambda(x ast.Lambda) value.Value {
+	typ := g.Types[x]
+	g.lltyp(typ)
+	_, ret, _ := typ.Signature()
+	id := fmt.Sprint(x.Pos.Row) + ":" + fmt.Sprint(x.Pos.Col)
+
+	params := []*ir.Param{ir.NewParam("env", types.I8Ptr)}
+	for _, param := range *x.Params {
+		params = append(params, ir.NewParam(param.Name.Name, g.lltyp(typing.Type(param.Type.Name))))
+	}
+	fun := g.mod.NewFunc(".lambda:"+id, g.lltyp(ret), params...)
+	fun.Linkage = enum.LinkagePrivate
+
+	fields := []types.Type{g.env}
+	for _, capture := range *x.Captures {
+		fields = append(fields, g.refs[capture.Outer.Type].ptr)
+	}
+	env := types.NewStruct(fields...)
+
+	g.genLambdaBody(fun, env, x)
+	if len(*x.Captures) == 0 {
+		return constant.NewStruct(g.fn.(*types.StructType), constant.NewBitCast(fun, types.I8Ptr), constant.NewNull(types.I8Ptr))
+	}
+
+	bl := g.bl
+	envptr := types.NewPointer(env)
+	end := constant.NewGetElementPtr(env, constant.NewNull(envptr), One)
+	mem := bl.NewCall(g.intrinsics["malloc"], constant.NewPtrToInt(end, types.I32))
+	header := bl.NewBitCast(mem, types.NewPointer(g.env))
+	bl.NewStore(One, g.envField(bl, header, 0))
+	bl.NewStore(g.genDrop(id, env, x), g.envField(bl, header, 1))
+
+	addr := bl.NewBitCast(mem, envptr)
+	for i, capture := range *x.Captures {
+		bundle := g.refs[capture.Outer.Type]
+		box := g.genBox(capture.Outer)
+		bl.NewCall(bundle.ref, box)
+
+		ptr := bl.NewGetElementPtr(env, addr, Zero, constant.NewInt(types.I32, int64(i+1)))
+		ptr.InBounds = true
+		store := bl.NewStore(box, ptr)
+		store.Align = 8
+	}
+
+	closure := bl.NewInsertValue(constant.NewUndef(g.fn.(*types.StructType)), constant.NewBitCast(fun, types.I8Ptr), 0)
+	fn := bl.NewInsertValue(closure, mem, 1)
+	g.top.Strings[fn] = typ
+	return fn
+}
+
+func (g *generator) genLambdaBody(fun *ir.Func, env types.Type, x ast.Lambda) {
+	bl, top, topfun := g.bl, g.top, g.topfun
+	g.ctx = &context{
+		g.ctx,
+		fun,
+		nil,
+		false,
+		utils.NewStack[*ir.Block](),
+		0,
+		nil,
+		nil,
+	}
+	g.bl, g.top, g.topfun = fun.NewBlock("entry"), x.Scope, x.FuncScope
+
+	g.genAllocas(g.topfun)
+	addr := g.bl.NewBitCast(fun.Params[0], types.NewPointer(env))
+	for i, capture := range *x.Captures {
+		bundle := g.refs[capture.Inner.Type]
+		ptr := g.bl.NewGetElementPtr(env, addr, Zero, constant.NewInt(types.I32, int64(i+1)))
+		ptr.InBounds = true
+		box := g.bl.NewLoad(bundle.ptr, ptr)
+		box.Align = 8
+		g.genBasicDecl(capture.Inner.Name, bundle.ptr, box, x.Loc())
+	}
+	for i, param := range *x.Params {
+		g.boxParam(x.Scope.Vars[param.Name.Name], fun.Params[i+1])
+	}
+
+	val := g.genExpr(x.Body)
+	if g.topfun.Return != typing.Void {
+		g.genHandover(val, g.topfun.Return)
+	}
+	g.destroy(g.top)
+	g.leaveRefs()
+	if g.topfun.Return == typing.Void {
+		g.bl.NewRet(nil)
+	} else {
+		g.bl.NewRet(val)
+	}
+
+	g.ctx = g.ctx.parent
+	g.bl, g.top, g.topfun = bl, top, topfun
+}
+
+// Dropping an environment lets go of every box it holds
+func (g *generator) genDrop(id string, env types.Type, x ast.Lambda) *ir.Func {
+	fun := g.mod.NewFunc(".drop:"+id, types.Void, ir.NewParam("", types.I8Ptr))
+	fun.Linkage = enum.LinkagePrivate
+
+	entry := fun.NewBlock("entry")
+	addr := entry.NewBitCast(fun.Params[0], types.NewPointer(env))
+	for i, capture := range *x.Captures {
+		bundle := g.refs[capture.Outer.Type]
+		ptr := entry.NewGetElementPtr(env, addr, Zero, constant.NewInt(types.I32, int64(i+1)))
+		ptr.InBounds = true
+		box := entry.NewLoad(bundle.ptr, ptr)
+		box.Align = 8
+		entry.NewCall(bundle.deref, box)
+	}
+	entry.NewCall(g.intrinsics["free"], fun.Params[0])
+	entry.NewRet(nil)
+	return fun
+}
+
+// Named functions are called through a thunk taking the environment they have no use for
+func (g *generator) genFuncValue(x ast.Identifier) value.Value {
+	var fun *builtins.FuncSignature
+	for i := range g.program.Functions {
+		if g.program.Functions[i].Name == x.Name {
+			fun = &g.program.Functions[i]
+			break
+		}
+	}
+	if fun == nil {
+		Errors.Fatal(Internal, "'"+x.Name+"' is not defined", x.Loc())
+	}
+	g.lltyp(g.Types[x])
+
+	thunk, ok := g.intrinsics[".thunk:"+x.Name]
+	if !ok {
+		params := []*ir.Param{ir.NewParam("", types.I8Ptr)}
+		args := []value.Value{}
+		for _, param := range fun.Ir.Params {
+			arg := ir.NewParam("", param.Typ)
+			params = append(params, arg)
+			args = append(args, arg)
+		}
+		thunk = g.mod.NewFunc(".thunk:"+x.Name, fun.Ir.Sig.RetType, params...)
+		thunk.Linkage = enum.LinkagePrivate
+		g.intrinsics[thunk.Name()] = thunk
+
+		entry := thunk.NewBlock("entry")
+		call := entry.NewCall(fun.Ir, args...)
+		call.CallingConv = fun.Ir.CallingConv
+		if fun.Return == typing.Void {
+			entry.NewRet(nil)
+		} else {
+			entry.NewRet(call)
+		}
+	}
+
+	return constant.NewStruct(g.fn.(*types.StructType), constant.NewBitCast(thunk, types.I8Ptr), constant.NewNull(types.I8Ptr))
+}
+
+func (g *generator) genValueCall(vari *ast.Variable, x ast.FuncCall) value.Value {
+	fn := g.genBasicIden(vari)
+	params, ret, _ := vari.Type.Signature()
+	args := g.genArgs(utils.Apply(params, builtins.QuickParam), *x.Params)
+
+	bl := g.bl
+	sig := types.NewFunc(g.lltyp(ret), append([]types.Type{types.I8Ptr}, g.lltyps(params)...)...)
+	code := bl.NewBitCast(bl.NewExtractValue(fn, 0), types.NewPointer(sig))
+	return g.genResult(bl.NewCall(code, append([]value.Value{bl.NewExtractValue(fn, 1)}, args...)...), ret)
+}
@@ -0,0 +1,225 @@
+package compiler
+
+import (
+	"sulfur/src/ast"
+	"sulfur/src/typing"
+
+	. "sulfur/src/errors"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// Every list is a pointer to the same runtime struct, which only knows the size of its items
+func (g *generator) listType(typ typing.Type) types.Type {
+	if g.list == nil {
+		g.genListRuntime()
+	}
+	ptr := types.NewPointer(g.list)
+	if _, ok := g.copys[typ]; ok {
+		return ptr
+	}
+
+	g.copys[typ] = g.mod.NewFunc(".copy:"+typ.String(), ptr, ir.NewParam("", ptr))
+	g.autofrees[typ] = g.mod.NewFunc(".free:"+typ.String(), types.Void, ir.NewParam("", ptr))
+	g.intrinsics[".clear:"+typ.String()] = g.mod.NewFunc(".clear:"+typ.String(), types.Void, ir.NewParam("", ptr))
+
+	g.genListCopy(typ)
+	g.genListClear(typ)
+	g.genListFree(typ)
+	return ptr
+}
+
+func (g *generator) genListRuntime() {
+	mod := g.mod
+	g.list = mod.NewTypeDef("type.list", types.NewStruct(
+		types.I32,   // length
+		types.I32,   // capacity
+		types.I32,   // size of an item
+		types.I8Ptr, // items
+	))
+	ptr := types.NewPointer(g.list)
+
+	runtime := []*ir.Func{
+		mod.NewFunc(".new:list", ptr, ir.NewParam("", types.I32)),
+		mod.NewFunc(".insert:list", types.I8Ptr, ir.NewParam("", ptr), ir.NewParam("", types.I32)),
+		mod.NewFunc(".remove:list", types.Void, ir.NewParam("", ptr), ir.NewParam("", types.I32)),
+		mod.NewFunc(".clear:list", types.Void, ir.NewParam("", ptr)),
+		mod.NewFunc(".copy:list", ptr, ir.NewParam("", ptr)),
+		mod.NewFunc(".free:list", types.Void, ir.NewParam("", ptr)),
+	}
+	for _, fun := range runtime {
+		fun.CallingConv = enum.CallingConvFast
+		g.intrinsics[fun.Name()] = fun
+	}
+}
+
+func (g *generator) genListCopy(typ typing.Type) {
+	item, _ := typ.ListItem()
+	fun := g.copys[typ]
+
+	entry := g.nonNull(fun)
+	list := entry.NewCall(g.intrinsics[".copy:list"], fun.Params[0])
+	list.CallingConv = enum.CallingConvFast
+
+	// The runtime only copies the items themselves, so anything they own is copied here
+	exit := entry
+	if g.owned(item) {
+		exit = g.eachListItem(fun, entry, list, item, func(bl *ir.Block, ptr value.Value) {
+			bl.NewStore(bl.NewCall(g.copys[item], bl.NewLoad(g.lltyp(item), ptr)), ptr)
+		})
+	}
+	exit.NewRet(list)
+}
+
+func (g *generator) genListClear(typ typing.Type) {
+	item, _ := typ.ListItem()
+	fun := g.intrinsics[".clear:"+typ.String()]
+
+	entry := fun.NewBlock("entry")
+	exit := entry
+	if g.owned(item) {
+		exit = g.eachListItem(fun, entry, fun.Params[0], item, func(bl *ir.Block, ptr value.Value) {
+			bl.NewCall(g.autofrees[item], bl.NewLoad(g.lltyp(item), ptr))
+		})
+	}
+
+	clear := exit.NewCall(g.intrinsics[".clear:list"], fun.Params[0])
+	clear.CallingConv = enum.CallingConvFast
+	exit.NewRet(nil)
+}
+
+func (g *generator) genListFree(typ typing.Type) {
+	fun := g.autofrees[typ]
+
+	entry := g.nonNull(fun)
+	entry.NewCall(g.intrinsics[".clear:"+typ.String()], fun.Params[0])
+	free := entry.NewCall(g.intrinsics[".free:list"], fun.Params[0])
+	free.CallingConv = enum.CallingConvFast
+	entry.NewRet(nil)
+}
+
+// Lists and maps are null until something is assigned to a field holding one, which leaves nothing to copy or free
+func (g *generator) nonNull(fun *ir.Func) *ir.Block {
+	param := fun.Params[0]
+	entry := fun.NewBlock("entry")
+	null := fun.NewBlock("null")
+	body := fun.NewBlock("body")
+	entry.NewCondBr(entry.NewICmp(enum.IPredEQ, param, constant.NewNull(param.Typ.(*types.PointerType))), null, body)
+	if fun.Sig.RetType.Equal(types.Void) {
+		null.NewRet(nil)
+	} else {
+		null.NewRet(param)
+	}
+	return body
+}
+
+// Builds a loop over a pointer to every item of a list, returning the block after it
+func (g *generator) eachListItem(fun *ir.Func, entry *ir.Block, list value.Value, item typing.Type, body func(bl *ir.Block, ptr value.Value)) *ir.Block {
+	length := entry.NewLoad(types.I32, g.listField(entry, list, 0))
+	items := entry.NewLoad(types.I8Ptr, g.listField(entry, list, 3))
+	addr := entry.NewBitCast(items, g.llptr(item))
+
+	return g.eachItem(fun, entry, length, func(bl *ir.Block, i value.Value) {
+		body(bl, bl.NewGetElementPtr(g.lltyp(item), addr, i))
+	})
+}
+
+func (g *generator) listField(bl *ir.Block, list value.Value, field int64) value.Value {
+	ptr := bl.NewGetElementPtr(g.list, list, Zero, constant.NewInt(types.I32, field))
+	ptr.InBounds = true
+	return ptr
+}
+
+// Lists own their items like arrays do, so owned items are copied on the way in
+func (g *generator) genListInsert(list, idx, val value.Value, item typing.Type) {
+	bl := g.bl
+	if g.owned(item) {
+		val = bl.NewCall(g.copys[item], val)
+	}
+
+	slot := bl.NewCall(g.intrinsics[".insert:list"], list, idx)
+	slot.CallingConv = enum.CallingConvFast
+	ptr := bl.NewBitCast(slot, g.llptr(item))
+	store := bl.NewStore(val, ptr)
+	store.Align = g.align(item)
+}
+
+// Removed items are no longer owned by the list, so owned ones are freed with the current scope instead
+func (g *generator) genListRemove(list, idx value.Value, item typing.Type) value.Value {
+	bl := g.bl
+	g.genBounds(idx, g.genListLength(list))
+	ptr := g.genListItemPtr(list, idx, item)
+	val := bl.NewLoad(g.lltyp(item), ptr)
+	val.Align = g.align(item)
+
+	remove := bl.NewCall(g.intrinsics[".remove:list"], list, idx)
+	remove.CallingConv = enum.CallingConvFast
+
+	if _, ok := g.builtins.classes[string(item)]; ok {
+		g.genResult(val, item)
+	} else if g.owned(item) {
+		g.top.Strings[val] = item
+	}
+	return val
+}
+
+func (g *generator) genList(x ast.List) value.Value {
+	item := typing.Type(x.Type.Name)
+	typ := typing.List(item)
+	g.lltyp(typ)
+
+	size := constant.NewInt(types.I32, int64(g.size(item)))
+	list := g.bl.NewCall(g.intrinsics[".new:list"], size)
+	list.CallingConv = enum.CallingConvFast
+
+	for i, expr := range *x.Items {
+		val := g.genExpr(expr)
+		g.genListInsert(list, constant.NewInt(types.I32, int64(i)), val, item)
+	}
+
+	g.top.Strings[list] = typ
+	return list
+}
+
+func (g *generator) genListMethod(x ast.MethodCall, typ typing.Type) value.Value {
+	item, _ := typ.ListItem()
+	list := g.genHeld(x.Method.Parent)
+	params := []value.Value{}
+	for _, param := range *x.Params {
+		params = append(params, g.genExpr(param))
+	}
+
+	bl := g.bl
+	switch x.Method.Child.Name {
+	case "push":
+		g.genListInsert(list, g.genListLength(list), params[0], item)
+	case "pop":
+		return g.genListRemove(list, bl.NewSub(g.genListLength(list), One), item)
+	case "insert":
+		g.genListInsert(list, params[0], params[1], item)
+	case "remove":
+		return g.genListRemove(list, params[0], item)
+	case "clear":
+		bl.NewCall(g.intrinsics[".clear:"+typ.String()], list)
+	default:
+		Errors.Fatal(Internal, "The method "+x.Method.Child.Name+" of "+typ.String()+" is undefined", x.Loc())
+	}
+	return nil
+}
+
+func (g *generator) genListItemPtr(list, idx value.Value, item typing.Type) value.Value {
+	bl := g.bl
+	items := bl.NewLoad(types.I8Ptr, g.listField(bl, list, 3))
+	addr := bl.NewBitCast(items, g.llptr(item))
+	return bl.NewGetElementPtr(g.lltyp(item), addr, idx)
+}
+
+func (g *generator) genListLength(list value.Value) value.Value {
+	load := g.bl.NewLoad(types.I32, g.listField(g.bl, list, 0))
+	load.Align = 4
+	return load
+}
@@ -0,0 +1,309 @@
+package compiler
+
+import (
+	"sulfur/src/ast"
+	"sulfur/src/lexer"
+	"sulfur/src/typing"
+
+	. "sulfur/src/errors"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// Every map is a pointer to the same runtime hash table, which hashes and compares keys through the functions it is given
+func (g *generator) mapType(typ typing.Type) types.Type {
+	if g.hashmap == nil {
+		g.genMapRuntime()
+	}
+	ptr := types.NewPointer(g.hashmap)
+	if _, ok := g.copys[typ]; ok {
+		return ptr
+	}
+
+	key, val, _ := typ.Entry()
+	g.copys[typ] = g.mod.NewFunc(".copy:"+typ.String(), ptr, ir.NewParam("", ptr))
+	g.autofrees[typ] = g.mod.NewFunc(".free:"+typ.String(), types.Void, ir.NewParam("", ptr))
+	g.intrinsics[".set:"+typ.String()] = g.mod.NewFunc(".set:"+typ.String(), types.Void, ir.NewParam("", ptr), ir.NewParam("", g.lltyp(key)), ir.NewParam("", g.lltyp(val)))
+	g.intrinsics[".delete:"+typ.String()] = g.mod.NewFunc(".delete:"+typ.String(), types.Void, ir.NewParam("", ptr), ir.NewParam("", g.lltyp(key)))
+
+	g.genMapCopy(typ)
+	g.genMapFree(typ)
+	g.genMapSet(typ)
+	g.genMapDelete(typ)
+	return ptr
+}
+
+func (g *generator) genMapRuntime() {
+	mod := g.mod
+	hash := types.NewPointer(types.NewFunc(types.I32, types.I8Ptr))
+	equal := types.NewPointer(types.NewFunc(types.I1, types.I8Ptr, types.I8Ptr))
+	g.hashmap = mod.NewTypeDef("type.map", types.NewStruct(
+		types.I32,   // length
+		types.I32,   // capacity
+		types.I32,   // slots in use or deleted
+		types.I32,   // size of a key
+		types.I32,   // size of a value
+		types.I8Ptr, // keys
+		types.I8Ptr, // values
+		types.I8Ptr, // state of every slot
+		hash,
+		equal,
+	))
+	ptr := types.NewPointer(g.hashmap)
+
+	runtime := []*ir.Func{
+		mod.NewFunc(".new:map", ptr, ir.NewParam("", types.I32), ir.NewParam("", types.I32), ir.NewParam("", hash), ir.NewParam("", equal)),
+		mod.NewFunc(".find:map", types.I32, ir.NewParam("", ptr), ir.NewParam("", types.I8Ptr)),
+		mod.NewFunc(".get:map", types.I8Ptr, ir.NewParam("", ptr), ir.NewParam("", types.I8Ptr)),
+		mod.NewFunc(".insert:map", types.I32, ir.NewParam("", ptr), ir.NewParam("", types.I8Ptr)),
+		mod.NewFunc(".delete:map", types.Void, ir.NewParam("", ptr), ir.NewParam("", types.I32)),
+		mod.NewFunc(".next:map", types.I32, ir.NewParam("", ptr), ir.NewParam("", types.I32)),
+		mod.NewFunc(".copy:map", ptr, ir.NewParam("", ptr)),
+		mod.NewFunc(".free:map", types.Void, ir.NewParam("", ptr)),
+	}
+	for _, key := range []string{"int", "float", "bool", "string"} {
+		runtime = append(runtime,
+			mod.NewFunc(".hash:"+key, types.I32, ir.NewParam("", types.I8Ptr)),
+			mod.NewFunc(".equal:"+key, types.I1, ir.NewParam("", types.I8Ptr), ir.NewParam("", types.I8Ptr)),
+		)
+	}
+	for _, fun := range runtime {
+		fun.CallingConv = enum.CallingConvFast
+		g.intrinsics[fun.Name()] = fun
+	}
+}
+
+// Unsigned integers and enums are hashed and compared like integers
+func (g *generator) hashName(key typing.Type) string {
+	switch key {
+	case typing.Float, typing.Boolean, typing.String:
+		return string(key)
+	}
+	return "int"
+}
+
+func (g *generator) genMapCopy(typ typing.Type) {
+	key, val, _ := typ.Entry()
+	fun := g.copys[typ]
+
+	entry := g.nonNull(fun)
+	m := entry.NewCall(g.intrinsics[".copy:map"], fun.Params[0])
+	m.CallingConv = enum.CallingConvFast
+
+	// The runtime only copies the slots themselves, so anything they own is copied here
+	exit := entry
+	if g.owned(key) || g.owned(val) {
+		exit = g.eachEntry(fun, entry, m, func(bl *ir.Block, i value.Value) {
+			for _, part := range []struct {
+				typ   typing.Type
+				field int64
+			}{{key, 5}, {val, 6}} {
+				if g.owned(part.typ) {
+					ptr := g.slotPtr(bl, m, part.field, i, part.typ)
+					bl.NewStore(bl.NewCall(g.copys[part.typ], bl.NewLoad(g.lltyp(part.typ), ptr)), ptr)
+				}
+			}
+		})
+	}
+	exit.NewRet(m)
+}
+
+func (g *generator) genMapFree(typ typing.Type) {
+	key, val, _ := typ.Entry()
+	fun := g.autofrees[typ]
+
+	entry := g.nonNull(fun)
+	exit := entry
+	if g.owned(key) || g.owned(val) {
+		exit = g.eachEntry(fun, entry, fun.Params[0], func(bl *ir.Block, i value.Value) {
+			g.freeEntry(bl, fun.Params[0], i, key, val)
+		})
+	}
+
+	free := exit.NewCall(g.intrinsics[".free:map"], fun.Params[0])
+	free.CallingConv = enum.CallingConvFast
+	exit.NewRet(nil)
+}
+
+// Setting a key the map already has only replaces the value, so the key given is only copied for new entries
+func (g *generator) genMapSet(typ typing.Type) {
+	key, val, _ := typ.Entry()
+	fun := g.intrinsics[".set:"+typ.String()]
+	m := fun.Params[0]
+
+	entry := fun.NewBlock("entry")
+	added := fun.NewBlock("added")
+	found := fun.NewBlock("found")
+	set := fun.NewBlock("set")
+
+	ptr := g.keyPtr(entry, fun.Params[1])
+	old := entry.NewCall(g.intrinsics[".find:map"], m, ptr)
+	old.CallingConv = enum.CallingConvFast
+	entry.NewCondBr(entry.NewICmp(enum.IPredEQ, old, NegOne), added, found)
+
+	idx := added.NewCall(g.intrinsics[".insert:map"], m, ptr)
+	idx.CallingConv = enum.CallingConvFast
+	if g.owned(key) {
+		added.NewStore(added.NewCall(g.copys[key], fun.Params[1]), g.slotPtr(added, m, 5, idx, key))
+	}
+	added.NewBr(set)
+
+	if g.owned(val) {
+		prev := found.NewLoad(g.lltyp(val), g.slotPtr(found, m, 6, old, val))
+		found.NewCall(g.autofrees[val], prev)
+	}
+	found.NewBr(set)
+
+	i := set.NewPhi(ir.NewIncoming(idx, added), ir.NewIncoming(old, found))
+	var v value.Value = fun.Params[2]
+	if g.owned(val) {
+		v = set.NewCall(g.copys[val], v)
+	}
+	set.NewStore(v, g.slotPtr(set, m, 6, i, val))
+	set.NewRet(nil)
+}
+
+func (g *generator) genMapDelete(typ typing.Type) {
+	key, val, _ := typ.Entry()
+	fun := g.intrinsics[".delete:"+typ.String()]
+	m := fun.Params[0]
+
+	entry := fun.NewBlock("entry")
+	found := fun.NewBlock("found")
+	exit := fun.NewBlock("exit")
+
+	idx := entry.NewCall(g.intrinsics[".find:map"], m, g.keyPtr(entry, fun.Params[1]))
+	idx.CallingConv = enum.CallingConvFast
+	entry.NewCondBr(entry.NewICmp(enum.IPredEQ, idx, NegOne), exit, found)
+
+	g.freeEntry(found, m, idx, key, val)
+	del := found.NewCall(g.intrinsics[".delete:map"], m, idx)
+	del.CallingConv = enum.CallingConvFast
+	found.NewBr(exit)
+
+	exit.NewRet(nil)
+}
+
+func (g *generator) freeEntry(bl *ir.Block, m, i value.Value, key, val typing.Type) {
+	if g.owned(key) {
+		bl.NewCall(g.autofrees[key], bl.NewLoad(g.lltyp(key), g.slotPtr(bl, m, 5, i, key)))
+	}
+	if g.owned(val) {
+		bl.NewCall(g.autofrees[val], bl.NewLoad(g.lltyp(val), g.slotPtr(bl, m, 6, i, val)))
+	}
+}
+
+// Builds a loop over every slot in use, returning the block after it
+func (g *generator) eachEntry(fun *ir.Func, entry *ir.Block, m value.Value, body func(bl *ir.Block, i value.Value)) *ir.Block {
+	cond := fun.NewBlock("")
+	loop := fun.NewBlock("")
+	exit := fun.NewBlock("")
+
+	first := entry.NewCall(g.intrinsics[".next:map"], m, Zero)
+	first.CallingConv = enum.CallingConvFast
+	entry.NewBr(cond)
+
+	i := cond.NewPhi(ir.NewIncoming(first, entry))
+	cond.NewCondBr(cond.NewICmp(enum.IPredNE, i, NegOne), loop, exit)
+
+	body(loop, i)
+	next := loop.NewCall(g.intrinsics[".next:map"], m, loop.NewAdd(i, One))
+	next.CallingConv = enum.CallingConvFast
+	i.Incs = append(i.Incs, ir.NewIncoming(next, loop))
+	loop.NewBr(cond)
+
+	return exit
+}
+
+// Finds the key or value in a slot, as the keys are in field 5 and the values in field 6
+func (g *generator) slotPtr(bl *ir.Block, m value.Value, field int64, i value.Value, typ typing.Type) value.Value {
+	ptr := bl.NewGetElementPtr(g.hashmap, m, Zero, constant.NewInt(types.I32, field))
+	ptr.InBounds = true
+	slots := bl.NewBitCast(bl.NewLoad(types.I8Ptr, ptr), g.llptr(typ))
+	return bl.NewGetElementPtr(g.lltyp(typ), slots, i)
+}
+
+// The runtime is given keys by address, so they are put on the stack of the function first
+func (g *generator) keyPtr(bl *ir.Block, key value.Value) value.Value {
+	alloca := bl.Parent.Blocks[0].NewAlloca(key.Type())
+	bl.NewStore(key, alloca)
+	return bl.NewBitCast(alloca, types.I8Ptr)
+}
+
+func (g *generator) genMap(x ast.Map) value.Value {
+	key, val := typing.Type(x.Key.Name), typing.Type(x.Value.Name)
+	typ := typing.Map(key, val)
+	g.lltyp(typ)
+
+	hash := g.hashName(key)
+	m := g.bl.NewCall(g.intrinsics[".new:map"],
+		constant.NewInt(types.I32, int64(g.size(key))),
+		constant.NewInt(types.I32, int64(g.size(val))),
+		g.intrinsics[".hash:"+hash],
+		g.intrinsics[".equal:"+hash],
+	)
+	m.CallingConv = enum.CallingConvFast
+
+	for i := range *x.Keys {
+		k := g.genExpr((*x.Keys)[i])
+		v := g.genExpr((*x.Values)[i])
+		g.bl.NewCall(g.intrinsics[".set:"+typ.String()], m, k, v)
+	}
+
+	g.top.Strings[m] = typ
+	return m
+}
+
+func (g *generator) genMapMethod(x ast.MethodCall, typ typing.Type) value.Value {
+	m := g.genHeld(x.Method.Parent)
+	key := g.genExpr((*x.Params)[0])
+
+	bl := g.bl
+	switch x.Method.Child.Name {
+	case "has":
+		idx := bl.NewCall(g.intrinsics[".find:map"], m, g.keyPtr(bl, key))
+		idx.CallingConv = enum.CallingConvFast
+		return bl.NewICmp(enum.IPredNE, idx, NegOne)
+	case "delete":
+		bl.NewCall(g.intrinsics[".delete:"+typ.String()], m, key)
+	default:
+		Errors.Fatal(Internal, "The method "+x.Method.Child.Name+" of "+typ.String()+" is undefined", x.Loc())
+	}
+	return nil
+}
+
+// Missing keys give the zero value, which the runtime points to instead of a slot
+func (g *generator) genMapGet(m, key value.Value, val typing.Type) value.Value {
+	bl := g.bl
+	ptr := bl.NewCall(g.intrinsics[".get:map"], m, g.keyPtr(bl, key))
+	ptr.CallingConv = enum.CallingConvFast
+	load := bl.NewLoad(g.lltyp(val), bl.NewBitCast(ptr, g.llptr(val)))
+	load.Align = g.align(val)
+	return load
+}
+
+func (g *generator) genMapAssignment(x ast.IndexAssignment) {
+	typ := g.Types[x.Index.Array]
+	_, val, _ := typ.Entry()
+	m := g.genHeld(x.Index.Array)
+	key := g.genExpr(x.Index.Index)
+	v := g.genExpr(x.Value)
+
+	if !lexer.Empty(x.Op) {
+		old := g.genMapGet(m, key, val)
+		v = g.genBinaryOperation(old, v, x.Op.Type, val, g.operand(x.Value), val)
+	}
+	g.bl.NewCall(g.intrinsics[".set:"+typ.String()], m, key, v)
+}
+
+func (g *generator) genMapLength(m value.Value) value.Value {
+	ptr := g.bl.NewGetElementPtr(g.hashmap, m, Zero, Zero)
+	ptr.InBounds = true
+	load := g.bl.NewLoad(types.I32, ptr)
+	load.Align = 4
+	return load
+}
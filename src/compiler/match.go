@@ -0,0 +1,117 @@
+package compiler
+
+import (
+	"fmt"
+	"sulfur/src/ast"
+	"sulfur/src/lexer"
+	"sulfur/src/typing"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/value"
+)
+
+func (g *generator) genMatch(x ast.Match) {
+	g.genArms(g.genExpr(x.Value), g.Types[x.Value], *x.Arms)
+}
+
+// Leaves the current block at the end of the arms
+func (g *generator) genArms(val value.Value, typ typing.Type, arms []ast.Arm) {
+	top := g.ctx.fun
+	id := g.id()
+
+	bodies := []*ir.Block{}
+	for i := range arms {
+		bodies = append(bodies, top.NewBlock("match.arm"+id+"."+fmt.Sprint(i)))
+	}
+	endBl := top.NewBlock("match.end" + id)
+
+	// Anything not matched goes to the else arm, which is always the last one
+	other := endBl
+	if len(*arms[len(arms)-1].Patterns) == 0 {
+		other = bodies[len(arms)-1]
+	}
+
+	if cases, ok := g.genCases(arms, bodies); ok {
+		g.bl.NewSwitch(val, other, cases...)
+	} else {
+		for i, arm := range arms {
+			if len(*arm.Patterns) == 0 {
+				break
+			}
+
+			var cond value.Value = constant.False
+			for _, pattern := range *arm.Patterns {
+				cond = g.bl.NewOr(cond, g.genPattern(val, typ, pattern))
+			}
+
+			next := other
+			if i < len(arms)-1 && len(*arms[i+1].Patterns) > 0 {
+				next = top.NewBlock("match.test" + id + "." + fmt.Sprint(i+1))
+			}
+			g.bl.NewCondBr(cond, bodies[i], next)
+			g.bl = next
+		}
+	}
+
+	for i, arm := range arms {
+		next := endBl
+		if arm.Fallthrough {
+			next = bodies[i+1]
+		}
+
+		g.top = arm.Body.Scope
+		g.ctx.exits.Push(next)
+		g.bl = bodies[i]
+		g.genBlock(arm.Body)
+		g.genDefers(g.top)
+		g.autoFree()
+		g.leaveRefs()
+		g.exit()
+		g.top = arm.Body.Scope.Parent
+	}
+	g.bl = endBl
+}
+
+// A switch is only used when every pattern is an integer known before running, where the first arm to have one wins
+func (g *generator) genCases(arms []ast.Arm, bodies []*ir.Block) ([]*ir.Case, bool) {
+	cases := []*ir.Case{}
+	seen := map[int64]bool{}
+	for i, arm := range arms {
+		for _, pattern := range *arm.Patterns {
+			switch x := pattern.(type) {
+			case ast.Integer, ast.UnsignedInteger:
+			case ast.Access:
+				if !g.isEnumAccess(x) {
+					return nil, false
+				}
+			default:
+				return nil, false
+			}
+
+			val, ok := g.genExpr(pattern).(*constant.Int)
+			if !ok {
+				return nil, false
+			}
+			if !seen[val.X.Int64()] {
+				seen[val.X.Int64()] = true
+				cases = append(cases, ir.NewCase(val, bodies[i]))
+			}
+		}
+	}
+	return cases, true
+}
+
+func (g *generator) genPattern(val value.Value, typ typing.Type, pattern ast.Expr) value.Value {
+	if x, ok := pattern.(ast.Range); ok {
+		start := g.genBasicComparison(val, g.genExpr(x.Start), lexer.GreaterThanOrEqualTo, typ)
+		end := g.genBasicComparison(val, g.genExpr(x.End), lexer.LessThanOrEqualTo, typ)
+		return g.bl.NewAnd(start, end)
+	}
+	if iden, ok := pattern.(ast.Identifier); ok {
+		if _, ok := g.builtins.classes[iden.Name]; ok {
+			return g.genBasicIs(val, typ, typing.Type(iden.Name))
+		}
+	}
+	return g.genBasicComparison(val, g.genExpr(pattern), lexer.EqualTo, typ)
+}
@@ -0,0 +1,71 @@
+package compiler
+
+import (
+	"sulfur/src/ast"
+	"sulfur/src/lexer"
+	"sulfur/src/typing"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/value"
+)
+
+// The type of an operand once it has been automatically converted
+func (g *generator) operand(x ast.Expr) typing.Type {
+	if conv, ok := g.AutoConvs[x]; ok {
+		return conv.To
+	}
+	return g.Types[x]
+}
+
+// Calls the function behind an operation if there is one, and otherwise generates it directly
+func (g *generator) genBinaryOperation(left, right value.Value, op lexer.TokenType, ltyp, rtyp, typ typing.Type) value.Value {
+	binop := g.srcBinop(op, string(ltyp), string(rtyp))
+	if binop == nil || !binop.Complex {
+		return g.genBasicBinaryOp(left, right, op, typ)
+	}
+
+	if binop.Swapped {
+		binop = g.srcBinop(op, string(rtyp), string(ltyp))
+		left, right = right, left
+	}
+	return g.genResult(g.bl.NewCall(binop.Ir, left, right), binop.Return)
+}
+
+func (g *generator) genUnaryOperation(val value.Value, op lexer.TokenType, valtyp, typ typing.Type) value.Value {
+	unop := g.srcUnop(op, string(valtyp))
+	if unop == nil || !unop.Complex {
+		return g.genBasicUnaryOp(val, op, typ)
+	}
+	return g.genResult(g.bl.NewCall(unop.Ir, val), unop.Return)
+}
+
+func (g *generator) genOperation(x ast.Operation) {
+	var fun *ir.Func
+	var ret typing.Type
+	switch len(x.Params) {
+	case 1:
+		unop := g.srcUnop(x.Op.Type, x.Params[0].Type.Name)
+		if unop == nil {
+			return
+		}
+		fun, ret = unop.Ir, unop.Return
+	case 2:
+		binop := g.srcBinop(x.Op.Type, x.Params[0].Type.Name, x.Params[1].Type.Name)
+		if binop == nil {
+			return
+		}
+		fun, ret = binop.Ir, binop.Return
+	default:
+		return
+	}
+
+	args := []value.Value{}
+	for _, param := range fun.Params {
+		args = append(args, param)
+	}
+
+	self, class := g.ctx.self, g.ctx.class
+	g.ctx.self, g.ctx.class = nil, nil
+	g.genFuncBody(fun, ret, x.Params, args, x.FuncScope, x.Body)
+	g.ctx.self, g.ctx.class = self, class
+}
@@ -1,8 +1,14 @@
 package compiler
 
 import (
+	"sulfur/src/ast"
+	"sulfur/src/typing"
+
 	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
 	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
 )
 
 type ref_bundle struct {
@@ -16,10 +22,72 @@ type ref_bundle struct {
 func (g *generator) leaveRefs() {
 	bl := g.bl
 	for _, vari := range g.top.ActiveRefs {
-		bundle := g.refs[vari.Type]
+		bl.NewCall(g.refs[vari.Type].deref, g.genBox(vari))
+	}
+}
+
+// Finds the box held by a referenced variable, where parameters are the box itself
+func (g *generator) genBox(vari *ast.Variable) value.Value {
+	if vari.Status == ast.Parameter {
+		return vari.Value
+	}
+	load := g.bl.NewLoad(g.refs[vari.Type].ptr, vari.Value)
+	load.Align = 8
+	return load
+}
+
+// Parameters passed by value are moved into a box when they are referenced or captured
+func (g *generator) boxParam(vari *ast.Variable, arg value.Value) {
+	vari.Value = arg
+	if !vari.Referenced || vari.References {
+		return
+	}
 
-		load := bl.NewLoad(bundle.ptr, vari.Value)
-		load.Align = 8
-		bl.NewCall(bundle.deref, load)
+	bundle := g.refs[vari.Type]
+	box := g.bl.NewCall(bundle.newref, arg)
+	if vari.Captured {
+		g.bl.NewCall(bundle.ref, box)
 	}
+	vari.Value = box
+}
+
+// Only primitives have boxes in the runtime, the rest are generated here without owning what they hold
+func (g *generator) genRefFuncs(typ typing.Type, bundle ref_bundle) {
+	lltyp := g.lltyp(typ)
+
+	entry := bundle.newref.NewBlock("entry")
+	mem := entry.NewCall(g.intrinsics["malloc"], constant.NewInt(types.I32, 16))
+	box := entry.NewBitCast(mem, bundle.ptr)
+	slot := entry.NewCall(g.intrinsics["malloc"], constant.NewInt(types.I32, int64(g.size(typ))))
+	addr := entry.NewBitCast(slot, types.NewPointer(lltyp))
+	store := entry.NewStore(bundle.newref.Params[0], addr)
+	store.Align = g.align(typ)
+	entry.NewStore(addr, g.refField(entry, bundle, box, 0))
+	entry.NewStore(Zero, g.refField(entry, bundle, box, 1))
+	entry.NewRet(box)
+
+	entry = bundle.ref.NewBlock("entry")
+	count := g.refField(entry, bundle, bundle.ref.Params[0], 1)
+	entry.NewStore(entry.NewAdd(entry.NewLoad(types.I32, count), One), count)
+	entry.NewRet(nil)
+
+	entry = bundle.deref.NewBlock("entry")
+	last := bundle.deref.NewBlock("last")
+	exit := bundle.deref.NewBlock("exit")
+	count = g.refField(entry, bundle, bundle.deref.Params[0], 1)
+	left := entry.NewSub(entry.NewLoad(types.I32, count), One)
+	entry.NewStore(left, count)
+	entry.NewCondBr(entry.NewICmp(enum.IPredEQ, left, Zero), last, exit)
+
+	held := last.NewLoad(types.NewPointer(lltyp), g.refField(last, bundle, bundle.deref.Params[0], 0))
+	last.NewCall(g.intrinsics["free"], last.NewBitCast(held, types.I8Ptr))
+	last.NewCall(g.intrinsics["free"], last.NewBitCast(bundle.deref.Params[0], types.I8Ptr))
+	last.NewBr(exit)
+	exit.NewRet(nil)
+}
+
+func (g *generator) refField(bl *ir.Block, bundle ref_bundle, box value.Value, field int64) value.Value {
+	ptr := bl.NewGetElementPtr(bundle.typ, box, Zero, constant.NewInt(types.I32, field))
+	ptr.InBounds = true
+	return ptr
 }
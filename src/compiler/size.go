@@ -14,9 +14,26 @@ func (g *generator) size(typ typing.Type) int {
 		return 1
 	case typing.String:
 		return 16
-	default:
-		return 0
 	}
+	if _, ok := typ.Item(); ok {
+		return 16
+	}
+	if _, ok := typ.ListItem(); ok {
+		return 8
+	}
+	if _, _, ok := typ.Entry(); ok {
+		return 8
+	}
+	if _, _, ok := typ.Signature(); ok {
+		return 16
+	}
+	if _, ok := g.builtins.classes[string(typ)]; ok {
+		return 8
+	}
+	if _, ok := g.builtins.enums[string(typ)]; ok {
+		return 4
+	}
+	return 0
 }
 
 func (g *generator) align(typ typing.Type) ir.Align {
@@ -26,6 +43,9 @@ func (g *generator) align(typ typing.Type) ir.Align {
 	case typing.Boolean:
 		return 1
 	default:
+		if _, ok := g.builtins.enums[string(typ)]; ok {
+			return 4
+		}
 		return 8
 	}
 }
@@ -11,7 +11,9 @@ import (
 	. "sulfur/src/errors"
 
 	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
 	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
 	"github.com/llir/llvm/ir/value"
 )
 
@@ -21,8 +23,13 @@ func (g *generator) genStmt(expr ast.Expr) {
 	}
 
 	switch x := expr.(type) {
+	case ast.NoExpr:
+		return
 	case ast.Declaration:
 		g.genBasicDecl(x.Name.Name, g.typ(x.Value), g.genExpr(x.Value), x.Name.Loc())
+		g.hold(x.Name.Name, x.Name.Loc())
+	case ast.Unpack:
+		g.genUnpack(x)
 	case ast.Assignment:
 		g.genAssignment(x)
 	case ast.IncDec:
@@ -35,6 +42,8 @@ func (g *generator) genStmt(expr ast.Expr) {
 		g.genIfStmt(x)
 	case ast.ForLoop:
 		g.genForLoop(x)
+	case ast.ForEach:
+		g.genForEach(x)
 	case ast.WhileLoop:
 		g.genWhileLoop(x)
 	case ast.DoWhileLoop:
@@ -47,6 +56,26 @@ func (g *generator) genStmt(expr ast.Expr) {
 		g.genBreak(x)
 	case ast.Continue:
 		g.genContinue(x)
+	case ast.Match:
+		g.genMatch(x)
+	case ast.Defer:
+		g.top.Defers = append(g.top.Defers, x.Value)
+	case ast.Throw:
+		g.genThrow(g.genExpr(x.Value))
+	case ast.Try:
+		g.genTry(x)
+	case ast.Class:
+		g.genClass(x)
+	case ast.Enum:
+		g.genEnum(x)
+	case ast.Operation:
+		g.genOperation(x)
+	case ast.FieldAssignment:
+		g.genFieldAssignment(x)
+	case ast.IndexAssignment:
+		g.genIndexAssignment(x)
+	case ast.MethodCall:
+		g.genMethodCall(x)
 	default:
 		fmt.Println("Ignored generating statement")
 	}
@@ -59,15 +88,25 @@ func (g *generator) genBlock(x ast.Block) {
 }
 
 func (g *generator) genAssignment(x ast.Assignment) {
-	if lexer.Empty(x.Op) {
-		g.genBasicAssign(x.Name.Name, g.genExpr(x.Value), x.Name.Loc())
-	} else {
-		vari := g.top.Lookup(x.Name.Name, x.Loc())
-		iden := g.genBasicIden(vari)
+	vari := g.top.Lookup(x.Name.Name, x.Loc())
+	val := g.genExpr(x.Value)
+	_, isObject := g.builtins.classes[string(vari.Type)]
 
-		val := g.genBasicBinaryOp(iden, g.genExpr(x.Value), x.Op.Type, g.Types[x.Value])
+	var old value.Value
+	if isObject || !lexer.Empty(x.Op) {
+		old = g.genBasicIden(vari)
+	}
+	if !lexer.Empty(x.Op) {
+		val = g.genBinaryOperation(old, val, x.Op.Type, vari.Type, g.operand(x.Value), g.Types[x.Value])
+	}
 
-		g.genBasicAssign(x.Name.Name, val, x.Name.Loc())
+	// The instance held before is let go of only after the new one is held, in case they are the same
+	if isObject {
+		g.bl.NewCall(g.copys[vari.Type], val)
+	}
+	g.genBasicAssign(x.Name.Name, val, x.Name.Loc())
+	if isObject {
+		g.bl.NewCall(g.autofrees[vari.Type], old)
 	}
 }
 
@@ -90,7 +129,7 @@ func (g *generator) genIncDec(x ast.IncDec) {
 	case typing.Float:
 		val = g.genBasicBinaryOp(iden, FOne, op, vari.Type)
 	default:
-		Errors.Error("Unexpected generating error during "+strings.ToLower(x.Op.Type.String()), x.Loc())
+		Errors.Fatal(Internal, "Unexpected generating error during "+strings.ToLower(x.Op.Type.String()), x.Loc())
 	}
 	g.genBasicAssign(x.Name.Name, val, x.Loc())
 }
@@ -101,63 +140,82 @@ func (g *generator) genFunction(x ast.Function) {
 		return
 	}
 
-	complex := g.complex(src.Return)
-	rettyp := g.lltyp(src.Return)
+	params := []value.Value{}
+	for _, param := range src.Params {
+		params = append(params, param.Ir)
+	}
+	g.genFuncBody(src.Ir, src.Return, x.Params, params, x.FuncScope, x.Body)
+}
+
+// Generates the body of a function, method, constructor or destructor, whose parameters are already declared
+func (g *generator) genFuncBody(fun *ir.Func, ret typing.Type, params []ast.Param, args []value.Value, fnscope *ast.FuncScope, body ast.Block) {
+	complex := g.complex(ret)
+	rettyp := g.lltyp(ret)
 
-	src.Ir.Linkage = enum.LinkagePrivate
+	fun.Linkage = enum.LinkagePrivate
 
-	entry := src.Ir.NewBlock("entry")
-	exit := src.Ir.NewBlock("exit")
+	entry := fun.NewBlock("entry")
+	exit := fun.NewBlock("exit")
 
 	exits := utils.NewStack[*ir.Block]()
 	exits.Push(exit)
 
-	var ret value.Value
-	if src.Return != typing.Void {
+	var retval value.Value
+	if ret != typing.Void {
 		alloca := entry.NewAlloca(rettyp)
 		alloca.LocalName = ".ret"
-		ret = alloca
-	} else {
-		ret = nil
-	}
+		retval = alloca
 
-	for i, param := range x.Params {
-		vari := x.Body.Scope.Vars[param.Name.Name]
-		vari.Value = src.Params[i].Ir
+		// Throwing returns whatever is left here, which is let go of like any other result
+		if fnscope.Throws != "" {
+			entry.NewStore(constant.NewZeroInitializer(rettyp), alloca)
+		}
+	} else {
+		retval = nil
 	}
 
 	g.ctx = &context{
 		g.ctx,
-		src.Ir,
-		ret,
+		fun,
+		retval,
 		complex,
 		exits,
 		0,
+		g.ctx.self,
+		g.ctx.class,
 	}
 
 	bl := g.bl
 	g.bl = entry
-	g.top = x.Body.Scope
-	g.topfun = x.FuncScope
+	g.top = body.Scope
+	g.topfun = fnscope
 
 	g.genAllocas(g.topfun)
+	for i, param := range params {
+		g.boxParam(body.Scope.Vars[param.Name.Name], args[i])
+	}
 
-	for _, expr := range x.Body.Body {
+	for _, expr := range body.Body {
 		g.genStmt(expr)
 	}
+	if g.bl.Term == nil {
+		g.genDefers(g.top)
+		g.autoFree()
+		g.leaveRefs()
+	}
 	g.exit()
 
-	if src.Return == typing.Void {
+	if ret == typing.Void {
 		exit.NewRet(nil)
 	} else {
-		load := exit.NewLoad(rettyp, ret)
+		load := exit.NewLoad(rettyp, retval)
 		exit.NewRet(load)
 	}
 
 	g.ctx = g.ctx.parent
 	g.bl = bl
-	g.top = x.Body.Scope.Parent
-	g.topfun = x.FuncScope.Parent
+	g.top = body.Scope.Parent
+	g.topfun = fnscope.Parent
 }
 
 func (g *generator) genIfStmt(x ast.IfStatement) {
@@ -188,6 +246,7 @@ func (g *generator) genIfStmt(x ast.IfStatement) {
 			g.bl = thenBl
 
 			g.genBlock(x.Body)
+			g.genDefers(g.top)
 			g.autoFree()
 			g.exit()
 
@@ -199,6 +258,7 @@ func (g *generator) genIfStmt(x ast.IfStatement) {
 			g.bl = elseBl
 
 			g.genBlock(x.Else)
+			g.genDefers(g.top)
 			g.autoFree()
 			g.exit()
 
@@ -231,6 +291,7 @@ func (g *generator) genForLoop(x ast.ForLoop) {
 
 		g.bl = bodyBl
 		g.genBlock(x.Body)
+		g.genDefers(g.top)
 		g.autoFree()
 
 		g.bl.NewBr(incBl)
@@ -246,6 +307,81 @@ func (g *generator) genForLoop(x ast.ForLoop) {
 	})
 }
 
+// The length is read on every iteration, as the body may change the length of a list
+func (g *generator) genForEach(x ast.ForEach) {
+	top := g.ctx.fun
+	id := g.id()
+
+	iter := g.genHeld(x.Iterable)
+	typ := g.Types[x.Iterable]
+	item := x.Body.Scope.Vars[x.Item.Name].Type
+	_, list := typ.ListItem()
+	_, _, hashmap := typ.Entry()
+
+	// Maps are walked slot by slot, skipping the ones not in use
+	next := func(bl *ir.Block, from value.Value) value.Value {
+		if !hashmap {
+			return from
+		}
+		call := bl.NewCall(g.intrinsics[".next:map"], iter, from)
+		call.CallingConv = enum.CallingConvFast
+		return call
+	}
+
+	// The position lives in the entry block, so that nested loops do not grow the stack
+	idx := top.Blocks[0].NewAlloca(types.I32)
+	idx.LocalName = ".idx" + id
+	g.bl.NewStore(next(g.bl, Zero), idx)
+	main := g.bl
+
+	g.scope(x.Body.Scope, func() {
+		condBl := top.NewBlock("for.cond" + id)
+		bodyBl := top.NewBlock("for.body" + id)
+		incBl := top.NewBlock("for.inc" + id)
+		endBl := top.NewBlock("for.end" + id)
+
+		x.Body.Scope.Entrance = incBl
+		x.Body.Scope.Exit = endBl
+
+		g.bl = condBl
+		i := condBl.NewLoad(types.I32, idx)
+		var more value.Value
+		switch {
+		case hashmap:
+			more = condBl.NewICmp(enum.IPredNE, i, NegOne)
+		case list:
+			more = condBl.NewICmp(enum.IPredSLT, i, g.genListLength(iter))
+		default:
+			more = condBl.NewICmp(enum.IPredSLT, i, condBl.NewExtractValue(iter, 0))
+		}
+
+		g.bl = bodyBl
+		var ptr value.Value
+		switch {
+		case hashmap:
+			ptr = g.slotPtr(bodyBl, iter, 5, i, item)
+		case list:
+			ptr = g.genListItemPtr(iter, i, item)
+		default:
+			ptr = bodyBl.NewGetElementPtr(g.lltyp(item), bodyBl.NewExtractValue(iter, 1), i)
+		}
+		load := bodyBl.NewLoad(g.lltyp(item), ptr)
+		load.Align = g.align(item)
+		g.genBasicDecl(x.Item.Name, g.lltyp(item), load, x.Item.Loc())
+
+		g.bl = condBl
+		g.block(bodyBl, incBl, func() { g.genBlock(x.Body) })
+
+		incBl.NewStore(next(incBl, incBl.NewAdd(incBl.NewLoad(types.I32, idx), One)), idx)
+		incBl.NewBr(condBl)
+
+		condBl.NewCondBr(more, bodyBl, endBl)
+
+		main.NewBr(condBl)
+		g.bl = endBl
+	})
+}
+
 func (g *generator) genWhileLoop(x ast.WhileLoop) {
 	main := g.bl
 	top := g.ctx.fun
@@ -316,25 +452,29 @@ func (g *generator) genLoop(x ast.Loop) {
 }
 
 func (g *generator) genReturn(x ast.Return) {
-	bl := g.bl
-
 	if g.ctx.ret != nil {
+		// A try in the value moves on to another block
 		val := g.genExpr(x.Value)
+		bl := g.bl
 		if g.ctx.complex {
 			store := bl.NewStore(val, g.ctx.ret)
 			store.Align = 8
 		} else {
 			bl.NewStore(val, g.ctx.ret)
 		}
+		g.genHandover(val, g.topfun.Return)
 	}
 
+	g.destroyUntil(func(scope *ast.Scope) bool { return scope.Seperate })
+
 	g.breaks[g.bl] = true
-	bl.NewBr(g.ctx.exits.Final())
+	g.bl.NewBr(g.ctx.exits.Final())
 }
 
 func (g *generator) genBreak(x ast.Break) {
 	bl := g.bl
 	exit := g.top.FindExit(x.Loc())
+	g.destroyUntil(func(scope *ast.Scope) bool { return scope.Exit != nil })
 
 	g.breaks[g.bl] = true
 	bl.NewBr(exit)
@@ -343,7 +483,27 @@ func (g *generator) genBreak(x ast.Break) {
 func (g *generator) genContinue(x ast.Continue) {
 	bl := g.bl
 	entrance := g.top.FindEntrance(x.Loc())
+	g.destroyUntil(func(scope *ast.Scope) bool { return scope.Entrance != nil })
 
 	g.breaks[g.bl] = true
 	bl.NewBr(entrance)
 }
+
+// Runs the statements deferred in a scope, newest first, unless it was already left. Whatever they create is released right away
+func (g *generator) genDefers(scope *ast.Scope) {
+	if g.bl.Term != nil {
+		return
+	}
+
+	top, strings, objects, owners := g.top, scope.Strings, scope.Objects, scope.Owners
+	g.top, scope.Owners = scope, []*ast.Variable{}
+	for i := len(scope.Defers) - 1; i >= 0; i-- {
+		scope.Strings, scope.Objects = map[value.Value]typing.Type{}, []ast.Object{}
+		g.genStmt(scope.Defers[i])
+		for val, typ := range scope.Strings {
+			g.bl.NewCall(g.autofrees[typ], val)
+		}
+		g.destroy(scope)
+	}
+	g.top, scope.Strings, scope.Objects, scope.Owners = top, strings, objects, owners
+}
@@ -0,0 +1,36 @@
+package compiler
+
+import (
+	"sulfur/src/ast"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// Tuples are plain structs, built from values that may already have been upcast
+func (g *generator) genTuple(x ast.Tuple) value.Value {
+	vals := []value.Value{}
+	fields := []types.Type{}
+	for _, item := range *x.Items {
+		val := g.genExpr(item)
+		vals = append(vals, val)
+		fields = append(fields, val.Type())
+	}
+
+	var tuple value.Value = constant.NewUndef(types.NewStruct(fields...))
+	for i, val := range vals {
+		tuple = g.bl.NewInsertValue(tuple, val, uint64(i))
+	}
+	return tuple
+}
+
+// Every value of a tuple is declared on its own
+func (g *generator) genUnpack(x ast.Unpack) {
+	tuple := g.genExpr(x.Value)
+	for i, name := range x.Names {
+		val := g.bl.NewExtractValue(tuple, uint64(i))
+		g.genBasicDecl(name.Name, val.Type(), val, name.Loc())
+		g.hold(name.Name, name.Loc())
+	}
+}
@@ -24,9 +24,38 @@ func (g *generator) lltyp(typ typing.Type) types.Type {
 	case typing.String:
 		return g.str
 	}
+	if elems, ok := typ.Elems(); ok {
+		return types.NewStruct(g.lltyps(elems)...)
+	}
+	if _, ok := typ.Item(); ok {
+		return g.arrayType(typ)
+	}
+	if _, ok := typ.ListItem(); ok {
+		return g.listType(typ)
+	}
+	if _, _, ok := typ.Entry(); ok {
+		return g.mapType(typ)
+	}
+	if _, _, ok := typ.Signature(); ok {
+		return g.funcType(typ)
+	}
+	if class, ok := g.builtins.classes[string(typ)]; ok {
+		return types.NewPointer(class.Ir)
+	}
+	if _, ok := g.builtins.enums[string(typ)]; ok {
+		return types.I32
+	}
 	return types.Void
 }
 
+func (g *generator) lltyps(typs []typing.Type) []types.Type {
+	lltyps := []types.Type{}
+	for _, typ := range typs {
+		lltyps = append(lltyps, g.lltyp(typ))
+	}
+	return lltyps
+}
+
 func (g *generator) llptr(typ typing.Type) types.Type {
 	return types.NewPointer(g.lltyp(typ))
 }
@@ -18,6 +18,7 @@ func (g *generator) block(entrance *ir.Block, exit *ir.Block, generate func()) {
 	g.enter(exit)
 	g.bl = entrance
 	generate()
+	g.genDefers(g.top)
 	g.autoFree()
 	g.exit()
 }
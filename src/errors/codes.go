@@ -0,0 +1,353 @@
+package errors
+
+import "strings"
+
+type Code string
+
+const (
+	// Lexing & parsing
+	UnterminatedString  Code = "E0001"
+	UnterminatedComment Code = "E0002"
+	UnknownToken        Code = "E0003"
+	InvalidNumber       Code = "E0004"
+	UnexpectedToken     Code = "E0005"
+	UnclosedDelimiter   Code = "E0006"
+	InvalidVisibility   Code = "E0007"
+
+	// Types
+	NoType              Code = "E0101"
+	MismatchedTypes     Code = "E0102"
+	UndefinedOperation  Code = "E0103"
+	UndefinedComparison Code = "E0104"
+	InvalidConversion   Code = "E0105"
+	MismatchedReference Code = "E0106"
+	ArgumentCount       Code = "E0107"
+	NotAnObject         Code = "E0108"
+	ClassReference      Code = "E0109"
+	InvalidOverride     Code = "E0110"
+	CyclicInheritance   Code = "E0111"
+	InvalidEnum         Code = "E0112"
+	NotAnArray          Code = "E0113"
+	InvalidKey          Code = "E0114"
+	ValueCount          Code = "E0115"
+	NotAFunction        Code = "E0116"
+	FunctionValue       Code = "E0117"
+	NonExhaustive       Code = "E0118"
+	NotAnError          Code = "E0119"
+
+	// Names
+	UndefinedVariable Code = "E0201"
+	UndefinedFunction Code = "E0202"
+	UndefinedType     Code = "E0203"
+	AlreadyDefined    Code = "E0204"
+	ParameterMutation Code = "E0205"
+	UndefinedMember   Code = "E0206"
+	PrivateMember     Code = "E0207"
+	ReadOnlyField     Code = "E0208"
+	SelfOutsideClass  Code = "E0209"
+	NoParent          Code = "E0210"
+
+	// Control flow
+	ReturnOutsideFunction Code = "E0301"
+	JumpOutsideLoop       Code = "E0302"
+	InvalidFallthrough    Code = "E0303"
+	InvalidDefer          Code = "E0304"
+	UnhandledError        Code = "E0305"
+	InvalidTry            Code = "E0306"
+
+	// Code generation
+	Internal Code = "E0901"
+)
+
+type Explanation struct {
+	Title       string
+	Description string
+	Example     string
+	Fix         string
+}
+
+var Explanations = map[Code]Explanation{
+	UnterminatedString: {
+		"unterminated string",
+		"A string literal was opened with a quote, but the file ended before it was closed. Strings may span several lines, so everything after the opening quote became part of it.",
+		`let name = "Sulfur`,
+		`let name = "Sulfur"`,
+	},
+	UnterminatedComment: {
+		"unterminated comment",
+		"A multiline comment was opened with /*, but the file ended before it was closed with */.",
+		"/* This comment never ends\nprintln(\"hello\")",
+		"/* This comment ends */\nprintln(\"hello\")",
+	},
+	UnknownToken: {
+		"unknown token",
+		"The parser found a token that cannot start or continue an expression here.",
+		"let x = 5 + )",
+		"let x = 5 + 1",
+	},
+	InvalidNumber: {
+		"invalid number literal",
+		"A number literal could not be read, usually because it is too large for its type or does not fit its suffix.",
+		"let half = 0.5u",
+		"let half = 0.5f",
+	},
+	UnexpectedToken: {
+		"unexpected token",
+		"The parser expected a specific token, such as a newline or a closing parenthesis, but found something else.",
+		"let x = 5 let y = 6",
+		"let x = 5\nlet y = 6",
+	},
+	UnclosedDelimiter: {
+		"unclosed delimiter",
+		"A parenthesis, brace or bracket was opened but never closed.",
+		"let x = 6\nif x > 5 {\n    println(\"big\")",
+		"let x = 6\nif x > 5 {\n    println(\"big\")\n}",
+	},
+	InvalidVisibility: {
+		"invalid visibility",
+		"Visibility modifiers such as pub and pri can only be placed before class fields, methods, constructors and destructors.",
+		"class Person {\n    pub to string {}\n}",
+		"class Person {\n    pub string name\n}",
+	},
+	NoType: {
+		"value has no type",
+		"An expression with no type, such as a call to a function without a return type, was used as a value.",
+		"func greet() {\n    println(\"hi\")\n}\nlet x = greet()",
+		"func greet() (string) {\n    return \"hi\"\n}\nlet x = greet()",
+	},
+	MismatchedTypes: {
+		"mismatched types",
+		"A value of one type was given where another type was expected, and no automatic conversion exists between them. Conversions can be done explicitly with type!(value).",
+		"let x: int = \"five\"",
+		"let x: int = 5\nlet y: string = string!(x)",
+	},
+	UndefinedOperation: {
+		"undefined operation",
+		"The operator is not defined for the types of its operands.",
+		"let x = true - false",
+		"let x = 1 - 0",
+	},
+	UndefinedComparison: {
+		"undefined comparison",
+		"The comparison is not defined for the types being compared.",
+		"if true < false {}",
+		"if 0 < 1 {}",
+	},
+	InvalidConversion: {
+		"invalid conversion",
+		"There is no conversion from the value's type to the requested type.",
+		"let x = int!(\"5\")",
+		"let x = int!(5.2)",
+	},
+	MismatchedReference: {
+		"mismatched reference",
+		"A reference was given where a plain value was expected, or the other way around.",
+		"func inc(&int x) {\n    x += 1\n}\nlet a = 5\ninc(a)",
+		"func inc(&int x) {\n    x += 1\n}\nlet a = 5\ninc(&a)",
+	},
+	ArgumentCount: {
+		"wrong number of arguments",
+		"A function was called with more or fewer arguments than it has parameters.",
+		"func add(int a, int b) (int) {\n    return a + b\n}\nadd(1)",
+		"func add(int a, int b) (int) {\n    return a + b\n}\nadd(1, 2)",
+	},
+	NotAnObject: {
+		"not an object",
+		"Fields and methods can only be accessed on instances of classes, and only classes can be created with new.",
+		"let x = 5\nprintln(string!(x.value))",
+		"class Box {\n    pub int value\n}\nlet x = new Box()\nprintln(string!(x.value))",
+	},
+	ClassReference: {
+		"reference to an instance",
+		"Instances of classes are always passed by reference, so they cannot be referenced with &.",
+		"class Box {\n    pub int value\n}\nfunc fill(&Box box) {\n    box.value = 5\n}",
+		"class Box {\n    pub int value\n}\nfunc fill(Box box) {\n    box.value = 5\n}",
+	},
+	InvalidOverride: {
+		"invalid override",
+		"A method replacing a method of its parent class must take the same parameters and return the same type, so that it can be called in place of the original.",
+		"class Animal {\n    pub speak(int times) {}\n}\nclass Dog extends Animal {\n    pub speak() {}\n}",
+		"class Animal {\n    pub speak(int times) {}\n}\nclass Dog extends Animal {\n    pub speak(int times) {}\n}",
+	},
+	CyclicInheritance: {
+		"cyclic inheritance",
+		"A class cannot extend itself, either directly or through the classes it extends.",
+		"class Chicken extends Egg {}\nclass Egg extends Chicken {}",
+		"class Bird {}\nclass Chicken extends Bird {}",
+	},
+	InvalidEnum: {
+		"invalid enum",
+		"Only integer enums are numbered automatically, so the values of other enums must all be given as literals. If one value of an enum is given, every other value must be given too.",
+		"enum Status {\n    Accepted\n    Rejected\n    None = -1\n}",
+		"enum Status {\n    Accepted = 0\n    Rejected = 1\n    None = -1\n}",
+	},
+	NotAnArray: {
+		"not an array",
+		"Only arrays, lists and maps can be indexed with square brackets or iterated over with for ... in. Arrays are created by writing the type of their items followed by the items, like int[1, 2, 3], lists like list[int](1, 2, 3) and maps like map[string]int{\"a\": 1}.",
+		"let x = 5\nprintln(string!(x[0]))",
+		"let x = int[5]\nprintln(string!(x[0]))",
+	},
+	InvalidKey: {
+		"invalid map key",
+		"The keys of a map have to be hashed and compared, which is only possible for int, uint, float, bool, string and enums.",
+		"let m = map[int[]]string{}",
+		"let m = map[int]string{}",
+	},
+	ValueCount: {
+		"mismatched value count",
+		"A function returning several values has to return all of them at once, and its result can only be unpacked into exactly as many variables as it has values.",
+		"func divmod(int a, int b) (int, int) {\n    return a / b, a % b\n}\nlet q = divmod(7, 2)",
+		"func divmod(int a, int b) (int, int) {\n    return a / b, a % b\n}\nlet q, r = divmod(7, 2)",
+	},
+	NotAFunction: {
+		"not a function",
+		"Only functions and variables holding a function type, such as func(int)(int), can be called.",
+		"let count = 5\nprintln(string!(count(2)))",
+		"let double = (int x) => x * 2\nprintln(string!(double(2)))",
+	},
+	FunctionValue: {
+		"invalid function value",
+		"Functions taking references or a variable number of arguments cannot be stored in a variable or passed around, as function types only describe parameters passed by value.",
+		"func inc(&int x) {\n    x++\n}\nlet f = inc",
+		"func inc(int x) (int) {\n    return x + 1\n}\nlet f = inc",
+	},
+	NonExhaustive: {
+		"non-exhaustive match",
+		"A match on an enum, or a catch with arms, has to handle every one of its values, either with its own arm or with an else arm.",
+		"enum Light {\n    Red\n    Green\n}\nmatch Light.Red {\n    Light.Red => println(\"stop\")\n}",
+		"enum Light {\n    Red\n    Green\n}\nmatch Light.Red {\n    Light.Red => println(\"stop\")\n    else => println(\"go\")\n}",
+	},
+	NotAnError: {
+		"not an error type",
+		"Only the values of error types, which are declared with the error keyword, can be thrown.",
+		"enum Problem {\n    Missing\n}\nfunc find() throws Problem {\n    throw Problem.Missing\n}",
+		"error Problem {\n    Missing\n}\nfunc find() throws Problem {\n    throw Problem.Missing\n}",
+	},
+	UndefinedVariable: {
+		"undefined variable",
+		"A variable was used before being declared, or is not visible from this scope. Functions can only see their own parameters and variables.",
+		"println(string!(count))",
+		"let count = 5\nprintln(string!(count))",
+	},
+	UndefinedFunction: {
+		"undefined function",
+		"A function was called that has not been declared.",
+		"printn(\"hello\")",
+		"println(\"hello\")",
+	},
+	UndefinedType: {
+		"undefined type",
+		"A type was named that is neither a builtin type nor a declared class or enum.",
+		"let x: strng = \"hi\"",
+		"let x: string = \"hi\"",
+	},
+	AlreadyDefined: {
+		"already defined",
+		"A variable was declared twice in the same scope, or a class member, operator or conversion was defined more than once.",
+		"let x = 5\nlet x = 6",
+		"let x = 5\nx = 6",
+	},
+	ParameterMutation: {
+		"illegal modification of a parameter",
+		"Parameters are immutable unless they are references.",
+		"func inc(int x) {\n    x += 1\n}",
+		"func inc(&int x) {\n    x += 1\n}",
+	},
+	UndefinedMember: {
+		"undefined member",
+		"The class has no field or method with this name, or a field was called like a method, or the other way around.",
+		"class Box {\n    pub int value\n}\nlet box = new Box()\nprintln(string!(box.valeu))",
+		"class Box {\n    pub int value\n}\nlet box = new Box()\nprintln(string!(box.value))",
+	},
+	PrivateMember: {
+		"private member",
+		"Fields and methods declared with pri can only be used from inside of their own class.",
+		"class Box {\n    pri int value\n}\nlet box = new Box()\nbox.value = 5",
+		"class Box {\n    pri int value\n    pub fill() {\n        .value = 5\n    }\n}\nlet box = new Box()\nbox.fill()",
+	},
+	ReadOnlyField: {
+		"read-only field",
+		"Fields declared with val can be read from anywhere, but can only be changed from inside of their own class.",
+		"class Box {\n    val int value\n}\nlet box = new Box()\nbox.value = 5",
+		"class Box {\n    val int value\n    new(int value) {\n        .value = value\n    }\n}\nlet box = new Box(5)",
+	},
+	SelfOutsideClass: {
+		"access outside of a class",
+		"A leading dot accesses a member of the current instance, which only exists inside of methods, constructors and destructors.",
+		"let value = .value",
+		"class Box {\n    pub int value\n    pub get() (int) {\n        return .value\n    }\n}",
+	},
+	NoParent: {
+		"no parent class",
+		"super accesses the class being extended, which only exists in classes declared with extends.",
+		"class Dog {\n    pub speak() {\n        super.speak()\n    }\n}",
+		"class Animal {\n    pub speak() {}\n}\nclass Dog extends Animal {\n    pub speak() {\n        super.speak()\n    }\n}",
+	},
+	ReturnOutsideFunction: {
+		"return outside of a function",
+		"A return statement can only be used inside a function body.",
+		"println(\"done\")\nreturn",
+		"func finish() {\n    println(\"done\")\n    return\n}",
+	},
+	JumpOutsideLoop: {
+		"break or continue outside of a loop",
+		"A break or continue statement can only be used inside a loop.",
+		"if true {\n    break\n}",
+		"loop {\n    break\n}",
+	},
+	InvalidFallthrough: {
+		"misplaced fallthrough",
+		"A fallthrough statement can only be the last statement of an arm of a match, and there has to be another arm after it to fall into.",
+		"match 1 {\n    1 => println(\"one\")\n    else => fallthrough\n}",
+		"match 1 {\n    1 => fallthrough\n    else => println(\"one or more\")\n}",
+	},
+	InvalidDefer: {
+		"invalid defer",
+		"Only function calls, method calls and assignments can be deferred. A deferred statement runs while its block is being left, so it cannot declare anything or jump somewhere else.",
+		"for let i = 0; i < 3; i++ {\n    defer break\n}",
+		"for let i = 0; i < 3; i++ {\n    defer println(\"done with \" + i)\n}",
+	},
+	UnhandledError: {
+		"unhandled error",
+		"An error that can be thrown has to be handled where it happens. A call to a function that throws has to be tried, and the error has to be caught or thrown on by a function declared to throw it.",
+		"error Problem {\n    Missing\n}\nfunc find() throws Problem {\n    throw Problem.Missing\n}\nfunc search() {\n    try find()\n}",
+		"error Problem {\n    Missing\n}\nfunc find() throws Problem {\n    throw Problem.Missing\n}\nfunc search() throws Problem {\n    try find()\n}",
+	},
+	InvalidTry: {
+		"invalid try",
+		"Only calls to functions that throw can be tried. As the rest of a statement is skipped when an error is thrown on, a try also has to be a statement of its own, or the whole value of a declaration, an assignment or a return. A try with catch arms has no value at all.",
+		"error Problem {\n    Missing\n}\nfunc find() (int) throws Problem {\n    throw Problem.Missing\n}\nprintln(string!(try find() catch 0))",
+		"error Problem {\n    Missing\n}\nfunc find() (int) throws Problem {\n    throw Problem.Missing\n}\nlet found = try find() catch 0\nprintln(string!(found))",
+	},
+	Internal: {
+		"internal compiler error",
+		"The compiler failed to generate code for something that passed type checking. This is a bug in the compiler, so please report it along with the code that caused it.",
+		"",
+		"",
+	},
+}
+
+func (code Code) Title() string {
+	return Explanations[code].Title
+}
+
+// Gives the long form description of an error code, including an example of the problem and how to fix it
+func Explain(code string) (string, bool) {
+	explanation, ok := Explanations[Code(strings.ToUpper(code))]
+	if !ok {
+		return "", false
+	}
+
+	text := strings.ToUpper(code) + ": " + explanation.Title + "\n\n" + explanation.Description + "\n"
+	if explanation.Example != "" {
+		text += "\nFor example, this code is erroneous:\n\n" + indent(explanation.Example) + "\n"
+	}
+	if explanation.Fix != "" {
+		text += "\nIt can be fixed like so:\n\n" + indent(explanation.Fix) + "\n"
+	}
+	return text, true
+}
+
+func indent(code string) string {
+	return "    " + strings.ReplaceAll(code, "\n", "\n    ")
+}
@@ -0,0 +1,38 @@
+package errors
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	text, ok := Explain("e0001")
+	if !ok {
+		t.Fatal("E0001 has no explanation")
+	}
+	if !strings.HasPrefix(text, "E0001: unterminated string\n\n") {
+		t.Errorf("unexpected heading in %q", text)
+	}
+	if !strings.Contains(text, "\n    let name = \"Sulfur\n") || !strings.Contains(text, "\n    let name = \"Sulfur\"\n") {
+		t.Errorf("the example and fix are not indented in %q", text)
+	}
+
+	for _, code := range []string{"", "E9999", "0001"} {
+		if _, ok := Explain(code); ok {
+			t.Errorf("%q should have no explanation", code)
+		}
+	}
+}
+
+func TestExplanations(t *testing.T) {
+	format := regexp.MustCompile(`^E\d{4}$`)
+	for code, explanation := range Explanations {
+		if !format.MatchString(string(code)) {
+			t.Errorf("%s is not of the form E0000", code)
+		}
+		if explanation.Title == "" || explanation.Description == "" {
+			t.Errorf("%s is missing a title or description", code)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package errors
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"sulfur/src/location"
 	"sulfur/src/settings"
@@ -14,19 +15,93 @@ const CodeBuffer = 4
 var Errors ErrorGenerator
 var Step = Compiling
 
+type Severity string
+
+const (
+	ErrorSeverity   Severity = "Error"
+	WarningSeverity Severity = "Warning"
+)
+
+type Warning string
+
+const (
+	UnusedVariable  Warning = "unused-variable"
+	UnusedParameter Warning = "unused-parameter"
+	UnusedFunction  Warning = "unused-function"
+	Shadowing       Warning = "shadow"
+	Unreachable     Warning = "unreachable"
+	Conversion      Warning = "conversion"
+)
+
+type Label struct {
+	Msg string
+	Loc *location.Location
+}
+
+type Diagnostic struct {
+	Severity Severity
+	Step     CompileStep
+	Code     string
+	Msg      string
+	Loc      *location.Location
+	Labels   []Label
+}
+
 type ErrorGenerator struct {
-	lines []string
+	file        string
+	lines       []string
+	diagnostics []Diagnostic
+	errors      int
+}
+
+func Note(msg string, loc *location.Location) Label {
+	return Label{msg, loc}
 }
 
 func size(num int) int {
 	return len(fmt.Sprint(num))
 }
 
-func (gen *ErrorGenerator) message(msg, typ, colorStart, colorEnd string, loc *location.Location) string {
+func position(loc *location.Location) string {
 	row, col, _ := loc.Get()
+	return fmt.Sprint(row+1) + ":" + fmt.Sprint(col+1)
+}
+
+func (gen *ErrorGenerator) line(row, numSize int) string {
+	return fmt.Sprint(row+1) + ". " + strings.Repeat(" ", numSize-size(row+1)) + gen.lines[row] + "\n"
+}
 
-	numSize := size(row + 1)
+func (gen *ErrorGenerator) underline(loc *location.Location, row, numSize int, first, rest string) string {
+	startRow, startCol, _ := loc.Get()
+	endRow, endCol, _ := loc.End()
 
+	from, to := 0, len([]rune(gen.lines[row]))
+	if row == startRow {
+		from = startCol
+	}
+	if row == endRow {
+		to = endCol
+	}
+
+	mark := rest
+	if row == startRow {
+		mark = first
+	}
+	mark += strings.Repeat(rest, utils.Max(0, to-from-1))
+
+	return strings.Repeat(" ", utils.Max(0, numSize+from+2)) + mark
+}
+
+func (gen *ErrorGenerator) message(diag Diagnostic) string {
+	row, _, _ := diag.Loc.Get()
+	endRow, _, _ := diag.Loc.End()
+
+	numSize := size(endRow + 1)
+
+	colorStart, colorEnd := "\033[31m", "\033[0m"
+	if diag.Severity == WarningSeverity {
+		colorStart = "\033[33m"
+	}
 	if !settings.Colored {
 		colorStart, colorEnd = "", ""
 	}
@@ -36,35 +111,171 @@ func (gen *ErrorGenerator) message(msg, typ, colorStart, colorEnd string, loc *l
 		err += "\n"
 	}
 
-	err += colorStart + typ + " while " + string(Step) + ":" + colorEnd + "\n"
-	for i := utils.Max(row-4, 0); i <= row; i++ {
-		err += fmt.Sprint(i+1) + ". " + strings.Repeat(" ", numSize-size(i+1))
-		err += gen.lines[i] + "\n"
+	header := string(diag.Severity)
+	if diag.Code != "" {
+		header += "[" + diag.Code + "]"
+	}
+
+	header += " while " + string(diag.Step) + ":"
+	if title := Code(diag.Code).Title(); title != "" {
+		header += " " + title
 	}
 
-	sidebuf := strings.Repeat(" ", utils.Max(0, numSize+col+2))
-	err += sidebuf + "^\n"
+	err += colorStart + header + colorEnd + "\n"
+	for i := utils.Max(row-CodeBuffer, 0); i <= endRow && i < len(gen.lines); i++ {
+		err += gen.line(i, numSize)
+		if i >= row {
+			err += gen.underline(diag.Loc, i, numSize, "^", "~") + "\n"
+		}
+	}
 
-	err += colorStart + msg + " (" + fmt.Sprint(row+1) + ":" + fmt.Sprint(col+1) + ")" + colorEnd + "\n"
+	err += colorStart + diag.Msg + " (" + position(diag.Loc) + ")" + colorEnd + "\n"
+
+	for _, label := range diag.Labels {
+		labelRow, _, _ := label.Loc.Get()
+		labelEnd, _, _ := label.Loc.End()
+		labelSize := size(labelEnd + 1)
+
+		for i := labelRow; i <= labelEnd && i < len(gen.lines); i++ {
+			err += gen.line(i, labelSize)
+			err += gen.underline(label.Loc, i, labelSize, "-", "-")
+			if i == labelEnd {
+				err += " " + label.Msg + " (" + position(label.Loc) + ")"
+			}
+			err += "\n"
+		}
+	}
 	return err
 }
 
-func (gen *ErrorGenerator) Error(msg string, loc *location.Location) {
-	err := gen.message(msg, "Error", "\033[31m", "\033[0m", loc)
+func (gen *ErrorGenerator) add(diag Diagnostic) {
+	if diag.Loc == nil {
+		diag.Loc = location.NoLocation
+	}
+	labels := []Label{}
+	for _, label := range diag.Labels {
+		if label.Loc != nil && label.Loc != location.NoLocation {
+			labels = append(labels, label)
+		}
+	}
+	diag.Labels = labels
+	gen.diagnostics = append(gen.diagnostics, diag)
+}
+
+// Records an error and continues, so that later errors can be reported alongside it
+func (gen *ErrorGenerator) Error(code Code, msg string, loc *location.Location, labels ...Label) {
+	gen.error(Diagnostic{ErrorSeverity, Step, string(code), msg, loc, labels})
+}
 
+func (gen *ErrorGenerator) error(diag Diagnostic) {
 	if settings.Stacktrace {
-		panic(err)
-	} else {
-		fmt.Println(err)
+		panic(gen.message(diag))
+	}
+
+	gen.add(diag)
+	gen.errors++
+	if settings.MaxErrors > 0 && gen.errors >= settings.MaxErrors {
+		gen.Report()
+	}
+}
+
+// Records an error that cannot be recovered from, and stops compilation
+func (gen *ErrorGenerator) Fatal(code Code, msg string, loc *location.Location, labels ...Label) {
+	gen.Error(code, msg, loc, labels...)
+	gen.Report()
+}
+
+// Records a warning unless it has been disabled, or an error if warnings are treated as such
+func (gen *ErrorGenerator) Warn(id Warning, msg string, loc *location.Location, labels ...Label) {
+	if !settings.Warnings[string(id)] {
+		return
+	}
+
+	diag := Diagnostic{WarningSeverity, Step, string(id), msg, loc, labels}
+	if settings.WarningsAsErrors {
+		diag.Severity = ErrorSeverity
+		gen.error(diag)
+		return
+	}
+	gen.add(diag)
+}
+
+func (gen *ErrorGenerator) Diagnostics() []Diagnostic {
+	return append([]Diagnostic{}, gen.diagnostics...)
+}
+
+func (gen *ErrorGenerator) Failed() bool {
+	return gen.errors > 0
+}
+
+func (gen *ErrorGenerator) sort() {
+	sort.SliceStable(gen.diagnostics, func(i, j int) bool {
+		a, b := gen.diagnostics[i].Loc, gen.diagnostics[j].Loc
+		if a.Row != b.Row {
+			return a.Row < b.Row
+		}
+		return a.Col < b.Col
+	})
+}
+
+// Prints every collected diagnostic in order of location, exiting if any were errors
+func (gen *ErrorGenerator) Report() {
+	if settings.Diagnostics != settings.Text {
+		if gen.Failed() {
+			gen.Finish()
+			os.Exit(1)
+		}
+		return
+	}
+
+	gen.sort()
+	shown := 0
+	explain := ""
+	for _, diag := range gen.diagnostics {
+		if diag.Severity == ErrorSeverity {
+			if settings.MaxErrors > 0 && shown >= settings.MaxErrors {
+				continue
+			}
+			shown++
+		}
+		if _, ok := Explanations[Code(diag.Code)]; ok && explain == "" {
+			explain = diag.Code
+		}
+		fmt.Println(gen.message(diag))
+	}
+	if shown < gen.errors {
+		fmt.Println("... and " + fmt.Sprint(gen.errors-shown) + " more errors")
+	}
+	if explain != "" && gen.Failed() {
+		fmt.Println("For more information about an error, try `sulfur explain " + explain + "`")
+	}
+	gen.diagnostics = []Diagnostic{}
+
+	if gen.Failed() {
+		if gen.errors == 1 {
+			fmt.Println("Compilation failed with 1 error")
+		} else {
+			fmt.Println("Compilation failed with " + fmt.Sprint(gen.errors) + " errors")
+		}
 		os.Exit(1)
 	}
 }
 
-func (gen *ErrorGenerator) Warn(msg string, loc *location.Location) {
-	err := gen.message(msg, "Warning", "\033[33m", "\033[0m", loc)
-	fmt.Println(err)
+// Prints every remaining diagnostic, and is the only output in machine-readable modes
+func (gen *ErrorGenerator) Finish() {
+	gen.sort()
+	switch settings.Diagnostics {
+	case settings.JSON:
+		fmt.Println(gen.json())
+	case settings.SARIF:
+		fmt.Println(gen.sarif())
+	default:
+		gen.Report()
+		return
+	}
+	gen.diagnostics = []Diagnostic{}
 }
 
-func NewErrorGenerator(source string) ErrorGenerator {
-	return ErrorGenerator{strings.Split(source, "\n")}
+func NewErrorGenerator(file, source string) ErrorGenerator {
+	return ErrorGenerator{file, strings.Split(source, "\n"), []Diagnostic{}, 0}
 }
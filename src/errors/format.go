@@ -0,0 +1,163 @@
+package errors
+
+import (
+	"strings"
+	"sulfur/src/location"
+	"sulfur/src/utils"
+)
+
+type (
+	jsonPosition struct {
+		Row int `json:"row"`
+		Col int `json:"col"`
+	}
+
+	jsonLabel struct {
+		File    string       `json:"file"`
+		Start   jsonPosition `json:"start"`
+		End     jsonPosition `json:"end"`
+		Message string       `json:"message"`
+	}
+
+	jsonDiagnostic struct {
+		File     string       `json:"file"`
+		Start    jsonPosition `json:"start"`
+		End      jsonPosition `json:"end"`
+		Severity string       `json:"severity"`
+		Step     CompileStep  `json:"step"`
+		Code     string       `json:"code,omitempty"`
+		Message  string       `json:"message"`
+		Labels   []jsonLabel  `json:"labels"`
+	}
+)
+
+type (
+	sarifLog struct {
+		Version string     `json:"version"`
+		Schema  string     `json:"$schema"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name string `json:"name"`
+	}
+
+	sarifResult struct {
+		RuleID           string            `json:"ruleId,omitempty"`
+		Level            string            `json:"level"`
+		Message          sarifMessage      `json:"message"`
+		Locations        []sarifLocation   `json:"locations"`
+		RelatedLocations []sarifLocation   `json:"relatedLocations,omitempty"`
+		Properties       map[string]string `json:"properties"`
+	}
+
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+		Message          *sarifMessage         `json:"message,omitempty"`
+	}
+
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifact `json:"artifactLocation"`
+		Region           sarifRegion   `json:"region"`
+	}
+
+	sarifArtifact struct {
+		URI string `json:"uri"`
+	}
+
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn"`
+		EndLine     int `json:"endLine"`
+		EndColumn   int `json:"endColumn"`
+	}
+)
+
+func (gen *ErrorGenerator) span(loc *location.Location) (jsonPosition, jsonPosition) {
+	row, col, _ := loc.Get()
+	endRow, endCol, _ := loc.End()
+	return jsonPosition{row + 1, col + 1}, jsonPosition{endRow + 1, endCol + 1}
+}
+
+func (gen *ErrorGenerator) fileOf(loc *location.Location) string {
+	if loc.File != "" {
+		return loc.File
+	}
+	return gen.file
+}
+
+func (gen *ErrorGenerator) sarifLocation(loc *location.Location, msg *sarifMessage) sarifLocation {
+	start, end := gen.span(loc)
+	return sarifLocation{
+		sarifPhysicalLocation{
+			sarifArtifact{gen.fileOf(loc)},
+			sarifRegion{start.Row, start.Col, end.Row, end.Col},
+		},
+		msg,
+	}
+}
+
+func (gen *ErrorGenerator) json() string {
+	diags := []jsonDiagnostic{}
+	for _, diag := range gen.diagnostics {
+		labels := []jsonLabel{}
+		for _, label := range diag.Labels {
+			start, end := gen.span(label.Loc)
+			labels = append(labels, jsonLabel{gen.fileOf(label.Loc), start, end, label.Msg})
+		}
+
+		start, end := gen.span(diag.Loc)
+		diags = append(diags, jsonDiagnostic{
+			gen.fileOf(diag.Loc),
+			start,
+			end,
+			strings.ToLower(string(diag.Severity)),
+			diag.Step,
+			diag.Code,
+			diag.Msg,
+			labels,
+		})
+	}
+	return utils.JSON(diags)
+}
+
+func (gen *ErrorGenerator) sarif() string {
+	results := []sarifResult{}
+	for _, diag := range gen.diagnostics {
+		related := []sarifLocation{}
+		for _, label := range diag.Labels {
+			related = append(related, gen.sarifLocation(label.Loc, &sarifMessage{label.Msg}))
+		}
+
+		results = append(results, sarifResult{
+			diag.Code,
+			strings.ToLower(string(diag.Severity)),
+			sarifMessage{diag.Msg},
+			[]sarifLocation{gen.sarifLocation(diag.Loc, nil)},
+			related,
+			map[string]string{"step": string(diag.Step)},
+		})
+	}
+
+	return utils.JSON(sarifLog{
+		"2.1.0",
+		"https://json.schemastore.org/sarif-2.1.0.json",
+		[]sarifRun{{
+			sarifTool{sarifDriver{"sulfur"}},
+			results,
+		}},
+	})
+}
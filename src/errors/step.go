@@ -3,11 +3,14 @@ package errors
 type CompileStep string
 
 const (
-	Compiling    CompileStep = "compiling"
-	Lexing       CompileStep = "lexing"
-	Parsing      CompileStep = "parsing"
-	Inferring    CompileStep = "inferring"
-	FlowAnalysis CompileStep = "flow analysis"
-	Optimizing   CompileStep = "optimizing"
-	Generating   CompileStep = "generating"
+	Compiling     CompileStep = "compiling"
+	Lexing        CompileStep = "lexing"
+	Parsing       CompileStep = "parsing"
+	Inferring     CompileStep = "inferring"
+	FlowAnalysis  CompileStep = "flow analysis"
+	Optimizing    CompileStep = "optimizing"
+	Generating    CompileStep = "generating"
+	Assembling    CompileStep = "assembling"
+	Disassembling CompileStep = "disassembling"
+	Linking       CompileStep = "linking"
 )
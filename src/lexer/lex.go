@@ -65,12 +65,18 @@ func (l *lexer) next(count int) string {
 }
 
 func (l *lexer) addAt(tt TokenType, value string, loc location.Location) {
+	end := l.loc
+	if loc.Idx == l.loc.Idx && tt != EOF {
+		size := len([]rune(value))
+		end.Col += size
+		end.Idx += size
+	}
+
 	token := NewToken(
 		tt,
 		value,
-		loc.Row,
-		loc.Col,
-		loc.Idx,
+		loc,
+		end,
 	)
 	l.tokens = append(l.tokens, *token)
 }
@@ -160,14 +166,17 @@ func (l *lexer) end(ending string) bool {
 	return false
 }
 
-func Lex(source string) *[]Token {
+func Lex(file, source string) *[]Token {
+	start := *location.NoLocation
+	start.File = file
+
 	l := lexer{
 		[]rune(source),
 		[]Token{},
-		*location.NoLocation,
-		*location.NoLocation,
+		start,
+		start,
 		None,
-		*location.NoLocation,
+		start,
 	}
 
 	for l.loc.Idx != len(l.source) {
@@ -177,7 +186,8 @@ func Lex(source string) *[]Token {
 				l.start(String, "\"") {
 				continue
 			}
-			if l.iden == l.loc && decimal(l.at()) {
+			// ... is a spread and .. a range, not the start of a number
+			if l.iden == l.loc && decimal(l.at()) && !l.match("..") {
 				l.start(Number, "")
 				continue
 			}
@@ -213,6 +223,13 @@ func Lex(source string) *[]Token {
 					val = UnicodeEight.ReplaceAllStringFunc(val, escapeReplace)
 
 					tok.Value = val
+
+					loc := *tok.Location
+					loc.Col--
+					loc.Idx--
+					loc.EndCol++
+					loc.EndIdx++
+					tok.Location = &loc
 				}
 			} else if l.mode == SingleLineComment {
 				if l.end("\n") {
@@ -221,7 +238,7 @@ func Lex(source string) *[]Token {
 			} else if l.mode == MultiLineComment {
 				l.end("*/")
 			} else if l.mode == Number {
-				if !decimal(l.at()) {
+				if !decimal(l.at()) || l.match("..") {
 					num := l.get(l.begin, l.loc.Idx-l.begin.Idx)
 					if num == "." {
 						l.add(Access, num)
@@ -255,9 +272,14 @@ func Lex(source string) *[]Token {
 	if l.mode == None {
 		l.identifier()
 	} else if l.mode == String {
-		Errors.Error("Missing \" at the end of string", &l.loc)
+		// Strings can span lines, so the quote that opened it says more than the end of the file
+		quote := l.begin
+		quote.Col--
+		quote.Idx--
+		quote.EndRow, quote.EndCol, quote.EndIdx = l.begin.Row, l.begin.Col, l.begin.Idx
+		Errors.Error(UnterminatedString, "This string is never closed with \"", &quote)
 	} else if l.mode == MultiLineComment {
-		Errors.Error("Missing */ at the end of multiline comment", &l.loc)
+		Errors.Error(UnterminatedComment, "Missing */ at the end of multiline comment", &l.loc)
 	} else {
 		remaining := l.get(l.begin, l.loc.Idx-l.begin.Idx)
 		l.addAt(l.mode, remaining, l.begin)
@@ -86,6 +86,7 @@ const (
 	Static                         // 'stat'
 	From                           // 'from'
 	Enum                           // 'enum'
+	Error                          // 'error'
 	For                            // 'for'
 	In                             // 'in'
 	While                          // 'while'
@@ -99,6 +100,7 @@ const (
 	Null                           // 'null'
 	Nullish                        // '??'
 	Spread                         // '...'
+	Range                          // '..'
 	Semicolon                      // ';'
 	Import                         // 'import'
 	Export                         // 'export'
@@ -141,6 +143,7 @@ var Keywords = map[string]TokenType{
 	"stat":        Static,
 	"from":        From,
 	"enum":        Enum,
+	"error":       Error,
 	"for":         For,
 	"in":          In,
 	"while":       While,
@@ -202,16 +205,20 @@ var Symbols = map[string]TokenType{
 	":":   Colon,
 	"??":  Nullish,
 	"...": Spread,
+	"..":  Range,
 	";":   Semicolon,
 	"=>":  Arrow,
 	"@":   Atsign,
 }
 
-func NewToken(tokentype TokenType, value string, row, col, idx int) *Token {
+func NewToken(tokentype TokenType, value string, start, end location.Location) *Token {
+	loc := start
+	loc.EndRow, loc.EndCol, loc.EndIdx = end.Row, end.Col, end.Idx
+
 	return &Token{
 		tokentype,
 		value,
-		location.NewLocation(row, col, idx),
+		&loc,
 	}
 }
 
@@ -355,6 +362,8 @@ func (tt TokenType) String() string {
 		return "From"
 	case Enum:
 		return "Enum"
+	case Error:
+		return "Error"
 	case For:
 		return "For"
 	case In:
@@ -379,6 +388,8 @@ func (tt TokenType) String() string {
 		return "Nullish"
 	case Spread:
 		return "Spread"
+	case Range:
+		return "Range"
 	case Semicolon:
 		return "Semicolon"
 	case Import:
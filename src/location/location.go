@@ -3,21 +3,60 @@ package location
 import "fmt"
 
 type Location struct {
-	Row int
-	Col int
-	Idx int
+	File   string
+	Row    int
+	Col    int
+	Idx    int
+	EndRow int
+	EndCol int
+	EndIdx int
 }
 
 func (loc Location) Get() (row, col, idx int) {
 	return loc.Row, loc.Col, loc.Idx
 }
 
+// The end of a location is exclusive, and locations without one cover a single character
+func (loc Location) End() (row, col, idx int) {
+	if loc.EndIdx <= loc.Idx {
+		return loc.Row, loc.Col + 1, loc.Idx + 1
+	}
+	return loc.EndRow, loc.EndCol, loc.EndIdx
+}
+
 func (loc *Location) MarshalJSON() ([]byte, error) {
-	return []byte("\"" + fmt.Sprint(loc.Row) + ":" + fmt.Sprint(loc.Col) + " #" + fmt.Sprint(loc.Idx) + "\""), nil
+	row, col, idx := loc.End()
+	return []byte("\"" + fmt.Sprint(loc.Row) + ":" + fmt.Sprint(loc.Col) + " #" + fmt.Sprint(loc.Idx) +
+		" - " + fmt.Sprint(row) + ":" + fmt.Sprint(col) + " #" + fmt.Sprint(idx) + "\""), nil
 }
 
 func NewLocation(row, col, idx int) *Location {
 	return &Location{
+		"",
+		row,
+		col,
+		idx,
+		row,
+		col,
+		idx,
+	}
+}
+
+// Creates a location covering everything from the start of one location to the end of another
+func Span(start, end *Location) *Location {
+	if start == nil || start == NoLocation {
+		return end
+	}
+	if end == nil || end == NoLocation {
+		return start
+	}
+
+	row, col, idx := end.End()
+	return &Location{
+		start.File,
+		start.Row,
+		start.Col,
+		start.Idx,
 		row,
 		col,
 		idx,
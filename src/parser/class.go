@@ -12,25 +12,51 @@ func (p *parser) parseClass() ast.Class {
 	tok := p.expect(lexer.Class)
 	name := p.parseIdentifier()
 
-	fields := []ast.Field{}
+	extends := ast.Identifier{}
+	if p.tt() == lexer.Extends {
+		p.eat()
+		extends = p.parseIdentifier()
+	}
+
+	class := ast.Class{
+		Pos:     tok.Location,
+		Fields:  []ast.Field{},
+		Methods: []ast.Method{},
+		Name:    name,
+		Extends: extends,
+	}
+
 	p.expect(lexer.OpenBrace)
+	p.blocks++
 	p.parseList(
 		func() {
-			stmt := p.parseClassStmt()
+			stmt := p.statement(p.parseClassStmt)
 			switch x := stmt.(type) {
 			case ast.Field:
-				fields = append(fields, x)
+				class.Fields = append(class.Fields, x)
+			case ast.Method:
+				class.Methods = append(class.Methods, x)
+			case ast.Operation:
+				class.Operations = append(class.Operations, x)
+			case ast.To:
+				class.Conversions = append(class.Conversions, x)
+			case ast.NewDel:
+				which, kind := &class.New, "constructor"
+				if x.Which == lexer.Delete {
+					which, kind = &class.Del, "destructor"
+				}
+
+				if !ast.Empty(*which) {
+					Errors.Error(AlreadyDefined, "The class "+name.Name+" already has a "+kind, x.Loc(), Note("previously defined here", which.Loc()))
+				} else {
+					*which = x
+				}
 			}
 		},
 		[]lexer.TokenType{lexer.CloseBrace},
 		[]lexer.TokenType{lexer.NewLine, lexer.Semicolon},
 	)
-
-	class := ast.Class{
-		Pos:    tok.Location,
-		Fields: fields,
-		Name:   name,
-	}
+	p.blocks--
 
 	fieldSigs := []builtins.FieldSignature{}
 	for _, field := range class.Fields {
@@ -40,17 +66,53 @@ func (p *parser) parseClass() ast.Class {
 			field.Name.Name,
 		))
 	}
-	sig := builtins.QuickClass(class.Name.Name, fieldSigs)
+
+	methodSigs := []builtins.MethodSignature{}
+	for _, method := range class.Methods {
+		methodSigs = append(methodSigs, builtins.QuickMethod(
+			method.Visibility.Type,
+			method.Name.Name,
+			returnType(method.Return),
+			p.paramSigs(method.Params)...,
+		))
+	}
+	for _, newdel := range []ast.NewDel{class.New, class.Del} {
+		if !ast.Empty(newdel) {
+			methodSigs = append(methodSigs, builtins.QuickMethod(
+				newdel.Visibility.Type,
+				newdel.Name(),
+				typing.Void,
+				p.paramSigs(newdel.Params)...,
+			))
+		}
+	}
+
+	for _, to := range class.Conversions {
+		conv := builtins.QuickModTypeConv("mod", typing.Type(name.Name), typing.Type(to.Type.Name))
+		p.program.TypeConvs = append(p.program.TypeConvs, conv)
+	}
+
+	sig := builtins.QuickModClass("mod", class.Name.Name, extends.Name, fieldSigs, methodSigs)
 	p.program.Classes = append(p.program.Classes, sig)
 
-	return ast.Class{}
+	return class
 }
 
 func (p *parser) parseClassStmt() ast.Expr {
 	if p.is(lexer.Visibility) {
 		return p.parseVisibleStmt()
 	}
+	if p.tt() == lexer.New || p.tt() == lexer.Delete {
+		return p.parseNewDel(lexer.Token{Type: lexer.Public})
+	}
+	if p.tt() == lexer.Operator {
+		return p.parseOperation()
+	}
+	if p.tt() == lexer.To {
+		return p.parseTo()
+	}
 
+	p.fail(UnexpectedToken, "Invalid class statement", p.at().Location)
 	return ast.NoExpr{}
 }
 
@@ -59,35 +121,20 @@ func (p *parser) parseVisibleStmt() ast.Expr {
 	if p.tt() == lexer.Identifier {
 		if p.ptt(1) == lexer.OpenParen {
 			name := p.parseIdentifier()
-			p.expect(lexer.OpenParen)
-			params := []ast.Param{}
-			p.parseList(
-				func() {
-					params = append(params, p.parseParam())
-				},
-				[]lexer.TokenType{lexer.CloseParen},
-				[]lexer.TokenType{lexer.Delimiter},
-			)
-
-			ret := ast.Identifier{}
-			if p.tt() == lexer.OpenParen {
-				p.expect(lexer.OpenParen)
-				ret = p.parseIdentifier()
-				p.expect(lexer.CloseParen)
-			}
+			params := p.parseParams()
 
-			body := p.parseBlock()
-
-			// TODO: Scope & signature
+			ret := p.parseReturns()
+			fnscope, body := p.parseFuncBody(returnType(ret))
 			return ast.Method{
 				Visibility: vis,
 				Name:       name,
 				Params:     params,
 				Return:     ret,
+				FuncScope:  fnscope,
 				Body:       body,
 			}
 		} else {
-			typ := p.parseIdentifier()
+			typ := p.parseType()
 			name := p.parseIdentifier()
 			return ast.Field{
 				Visibility: vis,
@@ -96,28 +143,46 @@ func (p *parser) parseVisibleStmt() ast.Expr {
 			}
 		}
 	} else if p.tt() == lexer.New || p.tt() == lexer.Delete {
-		which := p.expect(lexer.New, lexer.Delete)
-
-		p.expect(lexer.OpenParen)
-		params := []ast.Param{}
-		p.parseList(
-			func() {
-				params = append(params, p.parseParam())
-			},
-			[]lexer.TokenType{lexer.CloseParen},
-			[]lexer.TokenType{lexer.Delimiter},
-		)
-
-		body := p.parseBlock()
-
-		return ast.NewDel{
-			Visibility: vis,
-			Which:      which.Type,
-			Params:     params,
-			Body:       body,
-		}
+		return p.parseNewDel(vis)
 	}
 
-	Errors.Error("No visiblility-togglable statement has been implemented similar to this", vis.Location)
+	p.fail(InvalidVisibility, "No visiblility-togglable statement has been implemented similar to this", vis.Location)
 	return ast.NoExpr{}
 }
+
+func (p *parser) parseNewDel(vis lexer.Token) ast.NewDel {
+	which := p.expect(lexer.New, lexer.Delete)
+	pos := vis.Location
+	if pos == nil {
+		pos = which.Location
+	}
+
+	params := p.parseParams()
+	if which.Type == lexer.Delete && len(params) > 0 {
+		Errors.Error(ArgumentCount, "A destructor cannot have any parameters", params[0].Loc())
+	}
+
+	fnscope, body := p.parseFuncBody(typing.Void)
+	return ast.NewDel{
+		Pos:        pos,
+		Visibility: vis,
+		Which:      which.Type,
+		Params:     params,
+		FuncScope:  fnscope,
+		Body:       body,
+	}
+}
+
+// Conversions are written inside of the class being converted, and can access the instance like a method
+func (p *parser) parseTo() ast.To {
+	tok := p.expect(lexer.To)
+	typ := p.parseType()
+
+	fnscope, body := p.parseFuncBody(typing.Type(typ.Name))
+	return ast.To{
+		Pos:       tok.Location,
+		Type:      typ,
+		FuncScope: fnscope,
+		Body:      body,
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"sulfur/src/ast"
 	. "sulfur/src/errors"
 	"sulfur/src/lexer"
+	"sulfur/src/location"
+	"sulfur/src/typing"
+	"sulfur/src/utils"
 )
 
 func (p *parser) parsePossibleExpr() ast.Expr {
@@ -15,7 +18,62 @@ func (p *parser) parsePossibleExpr() ast.Expr {
 }
 
 func (p *parser) parseExpr() ast.Expr {
-	return p.parseLogical()
+	return p.parsePipe()
+}
+
+// Pipes pass the value on their left as the first argument of the call on their right, so x |> f |> g(2) is g(f(x), 2)
+func (p *parser) parsePipe() ast.Expr {
+	left := p.parseLogical()
+	for p.tt() == lexer.Pipe {
+		p.eat()
+		name := p.parseIdentifier()
+
+		params := []ast.Expr{left}
+		if p.tt() == lexer.OpenParen {
+			p.eat()
+			p.parseList(
+				func() {
+					params = append(params, p.parseArg())
+				},
+				[]lexer.TokenType{lexer.CloseParen},
+				[]lexer.TokenType{lexer.Delimiter},
+			)
+		}
+
+		left = ast.FuncCall{
+			End:    p.last().Location,
+			Func:   name,
+			Params: &params,
+		}
+	}
+	return left
+}
+
+// Whether the statement here is a pipe, which would otherwise only be parsed up to its first call
+func (p *parser) isPipe() bool {
+	depth := 0
+	for i := 0; p.idx+i < p.size; i++ {
+		switch p.ptt(i) {
+		case lexer.OpenParen, lexer.OpenBracket, lexer.OpenBrace:
+			depth++
+		case lexer.CloseParen, lexer.CloseBracket, lexer.CloseBrace:
+			depth--
+			if depth < 0 {
+				return false
+			}
+		case lexer.NewLine, lexer.Semicolon:
+			if depth == 0 {
+				return false
+			}
+		case lexer.Pipe:
+			if depth == 0 {
+				return true
+			}
+		case lexer.EOF:
+			return false
+		}
+	}
+	return false
 }
 
 func (p *parser) parseLogical() ast.Expr {
@@ -109,6 +167,7 @@ func (p *parser) parseTypeConv() ast.Expr {
 		p.expect(lexer.Not)
 		val := p.parseGroup()
 		return ast.TypeConv{
+			End:   p.last().Location,
 			Type:  typ,
 			Value: val,
 		}
@@ -124,16 +183,17 @@ func (p *parser) parseNew() ast.Expr {
 		params := []ast.Expr{}
 		p.parseList(
 			func() {
-				params = append(params, p.parseExpr())
+				params = append(params, p.parseArg())
 			},
 			[]lexer.TokenType{lexer.CloseParen},
 			[]lexer.TokenType{lexer.Delimiter},
 		)
-		return ast.New{
+		return p.parseIndexed(ast.New{
 			Pos:    new.Location,
+			End:    p.last().Location,
 			Class:  class,
 			Params: &params,
-		}
+		})
 	}
 	return p.parseReference()
 }
@@ -159,9 +219,24 @@ func (p *parser) parsePrimary() ast.Expr {
 		return p.parseNumber()
 	case lexer.String:
 		return p.parseString()
+	case lexer.Try:
+		return p.parseTry()
 	case lexer.OpenParen:
+		if p.isLambda() {
+			return p.parseLambda()
+		}
 		return p.parseGroup()
 	default:
+		if p.isContainer("list") {
+			return p.parseIndexed(p.parseNewList())
+		}
+		if p.isContainer("map") {
+			return p.parseIndexed(p.parseNewMap())
+		}
+		if p.tt() == lexer.Identifier && p.typeName(p.at().Value) && (p.ptt(1) == lexer.OpenBracket || p.ptt(1) == lexer.Index) {
+			return p.parseArray()
+		}
+
 		hybrid := p.parseHybrid()
 		if !ast.Empty(hybrid) {
 			return hybrid
@@ -170,7 +245,7 @@ func (p *parser) parsePrimary() ast.Expr {
 		}
 	}
 
-	Errors.Error("Unknown token '"+strings.ReplaceAll(p.at().Value, "\n", "\\n")+"'", p.at().Location)
+	p.fail(UnknownToken, "Unknown token '"+strings.ReplaceAll(p.at().Value, "\n", "\\n")+"'", p.at().Location)
 	return ast.NoExpr{
 		Pos: tok.Location,
 	}
@@ -199,24 +274,25 @@ func (p *parser) parseNumber() ast.Expr {
 			if f, ok := parseFloat(val, loc); ok {
 				return f
 			} else {
-				Errors.Error("Invalid float literal", loc)
+				Errors.Error(InvalidNumber, "Invalid float literal", loc)
 			}
 		case "u":
 			if u, ok := parseUnsignedInt(val, loc); ok {
 				return u
 			} else {
-				Errors.Error("Invalid unsigned integer literal", loc)
+				Errors.Error(InvalidNumber, "Invalid unsigned integer literal", loc)
 			}
 		default:
-			Errors.Error("Invalid numerical suffix", suf.Location)
+			Errors.Error(InvalidNumber, "Invalid numerical suffix", suf.Location)
 		}
 	} else if i, ok := parseInteger(val, loc); ok {
 		return i
 	} else if f, ok := parseFloat(val, loc); ok {
 		return f
+	} else {
+		Errors.Error(InvalidNumber, "Invalid number", tok.Location)
 	}
 
-	Errors.Error("Invalid number", tok.Location)
 	return ast.NoExpr{
 		Pos: tok.Location,
 	}
@@ -238,9 +314,241 @@ func (p *parser) parseIdentifier() ast.Identifier {
 	}
 }
 
+// Types are identifiers, followed by [] for arrays of them
+func (p *parser) parseType() ast.Identifier {
+	var typ ast.Identifier
+	if p.tt() == lexer.Function {
+		typ = p.parseFuncType()
+	} else {
+		typ = p.parseIdentifier()
+	}
+	if typ.Name == "list" && p.tt() == lexer.OpenBracket {
+		p.eat()
+		item := p.parseType()
+		tok := p.expect(lexer.CloseBracket)
+		typ = ast.Identifier{
+			Pos:  location.Span(typ.Pos, tok.Location),
+			Name: string(typing.List(typing.Type(item.Name))),
+		}
+	} else if typ.Name == "map" && p.tt() == lexer.OpenBracket {
+		p.eat()
+		key := p.parseType()
+		p.expect(lexer.CloseBracket)
+		val := p.parseType()
+		return ast.Identifier{
+			Pos:  location.Span(typ.Pos, val.Pos),
+			Name: string(typing.Map(typing.Type(key.Name), typing.Type(val.Name))),
+		}
+	}
+	for p.tt() == lexer.Index {
+		tok := p.eat()
+		typ = ast.Identifier{
+			Pos:  location.Span(typ.Pos, tok.Location),
+			Name: typ.Name + "[]",
+		}
+	}
+	return typ
+}
+
+// Function types list the types of their parameters and then of what they return, like func(int)(int)
+func (p *parser) parseFuncType() ast.Identifier {
+	tok := p.expect(lexer.Function)
+	p.expect(lexer.OpenParen)
+
+	params := []typing.Type{}
+	p.parseList(
+		func() {
+			params = append(params, typing.Type(p.parseType().Name))
+		},
+		[]lexer.TokenType{lexer.CloseParen},
+		[]lexer.TokenType{lexer.Delimiter},
+	)
+	ret := returnType(p.parseReturns())
+
+	return ast.Identifier{
+		Pos:  location.Span(tok.Location, p.last().Location),
+		Name: string(typing.Func(params, ret)),
+	}
+}
+
+// Whether a name is a type declared so far, which is how array literals are told apart from indexing
+func (p *parser) typeName(name string) bool {
+	if utils.Contains(typing.Primitives, typing.Type(name)) {
+		return true
+	}
+	for _, class := range p.program.Classes {
+		if class.Name == name {
+			return true
+		}
+	}
+	for _, enum := range p.program.Enums {
+		if enum.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Arrays are written as the type of their items followed by the items, like int[1, 2, 3], or int[] when empty
+func (p *parser) parseArray() ast.Array {
+	typ := p.parseIdentifier()
+
+	// Arrays of arrays have every [] but the last in the type of their items, like int[][int[1], int[2]]
+	for p.tt() == lexer.Index && (p.ptt(1) == lexer.OpenBracket || p.ptt(1) == lexer.Index) {
+		tok := p.eat()
+		typ = ast.Identifier{
+			Pos:  location.Span(typ.Pos, tok.Location),
+			Name: typ.Name + "[]",
+		}
+	}
+
+	items := []ast.Expr{}
+	if p.tt() == lexer.Index {
+		p.eat()
+	} else {
+		p.expect(lexer.OpenBracket)
+		p.parseList(
+			func() {
+				items = append(items, p.parseExpr())
+			},
+			[]lexer.TokenType{lexer.CloseBracket},
+			[]lexer.TokenType{lexer.Delimiter},
+		)
+	}
+
+	return ast.Array{
+		End:   p.last().Location,
+		Type:  typ,
+		Items: &items,
+	}
+}
+
+// Whether a list or map is created here, as list[int] would otherwise index a variable named list
+func (p *parser) isContainer(name string) bool {
+	if p.tt() != lexer.Identifier || p.at().Value != name || p.ptt(1) != lexer.OpenBracket {
+		return false
+	}
+	item := p.peek(2)
+	if item.Type == lexer.Function {
+		return true
+	}
+	if item.Type != lexer.Identifier {
+		return false
+	}
+	if item.Value == "list" || item.Value == "map" || p.typeName(item.Value) {
+		return true
+	}
+
+	// Types that are not declared yet can still be told apart by what follows them
+	after := lexer.OpenParen
+	if name == "map" {
+		after = lexer.Identifier
+	}
+	return p.ptt(3) == lexer.CloseBracket && p.ptt(4) == after
+}
+
+// Lists are created from the type of their items followed by any initial items, like list[int](1, 2, 3)
+func (p *parser) parseNewList() ast.List {
+	tok := p.at()
+	typ := p.parseType()
+	item, _ := typing.Type(typ.Name).ListItem()
+
+	p.expect(lexer.OpenParen)
+	items := []ast.Expr{}
+	p.parseList(
+		func() {
+			items = append(items, p.parseExpr())
+		},
+		[]lexer.TokenType{lexer.CloseParen},
+		[]lexer.TokenType{lexer.Delimiter},
+	)
+
+	return ast.List{
+		Pos:   tok.Location,
+		End:   p.last().Location,
+		Type:  ast.Identifier{Pos: typ.Pos, Name: string(item)},
+		Items: &items,
+	}
+}
+
+// Maps are created from the types of their keys and values followed by any entries, like map[string]int{"a": 1}
+func (p *parser) parseNewMap() ast.Map {
+	tok := p.at()
+	typ := p.parseType()
+	key, val, _ := typing.Type(typ.Name).Entry()
+
+	p.expect(lexer.OpenBrace)
+	keys, vals := []ast.Expr{}, []ast.Expr{}
+	p.parseList(
+		func() {
+			keys = append(keys, p.parseExpr())
+			p.expect(lexer.Colon)
+			vals = append(vals, p.parseExpr())
+		},
+		[]lexer.TokenType{lexer.CloseBrace},
+		[]lexer.TokenType{lexer.Delimiter},
+	)
+
+	return ast.Map{
+		Pos:    tok.Location,
+		End:    p.last().Location,
+		Key:    ast.Identifier{Pos: typ.Pos, Name: string(key)},
+		Value:  ast.Identifier{Pos: typ.Pos, Name: string(val)},
+		Keys:   &keys,
+		Values: &vals,
+	}
+}
+
 func (p *parser) parseGroup() ast.Expr {
 	p.expect(lexer.OpenParen)
 	body := p.parseExpr()
 	p.expect(lexer.CloseParen)
 	return body
 }
+
+// Whether the parenthesis here opens the parameters of a lambda rather than a group
+func (p *parser) isLambda() bool {
+	depth := 0
+	for i := 0; p.idx+i < p.size; i++ {
+		switch p.ptt(i) {
+		case lexer.OpenParen:
+			depth++
+		case lexer.CloseParen:
+			depth--
+			if depth == 0 {
+				return p.ptt(i+1) == lexer.Arrow
+			}
+		}
+	}
+	return false
+}
+
+// Lambdas are written like (int x) => x * 2, and give back whatever their body does
+func (p *parser) parseLambda() ast.Lambda {
+	pos := p.at().Location
+	params := p.parseParams()
+	for _, param := range params {
+		if param.Referenced || param.Variadic {
+			Errors.Error(UnexpectedToken, "The parameters of a lambda cannot be references or variadic", param.Loc())
+		}
+	}
+	p.expect(lexer.Arrow)
+
+	fnscope := ast.NewFuncScope(p.topfun, typing.Invalid)
+	scope := ast.NewScope()
+	scope.Parent = p.top
+	scope.Seperate = true
+
+	p.topfun, p.top = fnscope, scope
+	body := p.parseExpr()
+	p.topfun, p.top = fnscope.Parent, scope.Parent
+
+	return ast.Lambda{
+		Pos:       pos,
+		Params:    &params,
+		Body:      body,
+		Scope:     scope,
+		FuncScope: fnscope,
+		Captures:  &[]ast.Capture{},
+	}
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"sulfur/src/ast"
 	"sulfur/src/lexer"
+	"sulfur/src/location"
 	"sulfur/src/utils"
 )
 
@@ -14,11 +15,14 @@ func (p *parser) parseDeclaration() ast.Expr {
 	if p.is(lexer.Prefix) {
 		prefix := p.eat()
 		name := p.parseIdentifier()
+		if p.tt() == lexer.Delimiter {
+			return p.parseUnpack(prefix, name)
+		}
 
 		var annotation ast.Identifier
 		if p.tt() == lexer.Colon {
 			p.eat()
-			annotation = p.parseIdentifier()
+			annotation = p.parseType()
 		} else {
 			annotation = ast.Identifier{}
 		}
@@ -37,6 +41,23 @@ func (p *parser) parseDeclaration() ast.Expr {
 	return p.parseAssignment()
 }
 
+func (p *parser) parseUnpack(prefix lexer.Token, first ast.Identifier) ast.Unpack {
+	names := []ast.Identifier{first}
+	for p.tt() == lexer.Delimiter {
+		p.eat()
+		names = append(names, p.parseIdentifier())
+	}
+
+	p.expect(lexer.Assignment)
+	val := p.parseExpr()
+	return ast.Unpack{
+		Pos:    prefix.Location,
+		Prefix: prefix.Type,
+		Names:  names,
+		Value:  val,
+	}
+}
+
 func (p *parser) parseAssignment() ast.Expr {
 	if p.tt() == lexer.Identifier {
 		if p.ptt(1) == lexer.Assignment {
@@ -85,46 +106,162 @@ func (p *parser) parseFuncCall() ast.Expr {
 		params := []ast.Expr{}
 		p.parseList(
 			func() {
-				params = append(params, p.parseExpr())
+				params = append(params, p.parseArg())
 			},
 			[]lexer.TokenType{lexer.CloseParen},
 			[]lexer.TokenType{lexer.Delimiter},
 		)
-		return ast.FuncCall{
+		return p.parseIndexed(ast.FuncCall{
+			End:    p.last().Location,
 			Func:   iden,
 			Params: &params,
-		}
+		})
 	}
 
 	return p.parseAccess()
 }
 
+// Arguments may spread an array into a variadic parameter
+func (p *parser) parseArg() ast.Expr {
+	if p.tt() == lexer.Spread {
+		spread := p.eat()
+		return ast.Spread{
+			Pos:   spread.Location,
+			Value: p.parseExpr(),
+		}
+	}
+	return p.parseExpr()
+}
+
 func (p *parser) parseAccess() ast.Expr {
-	if p.tt() == lexer.Access || p.ptt(1) == lexer.Access {
-		iden := ast.Identifier{}
+	if p.tt() == lexer.Access || p.tt() == lexer.Identifier && p.ptt(1) == lexer.Access {
+		// A leading dot accesses the current instance
+		var parent ast.Expr = ast.Identifier{}
 		pos := p.at().Location
 		if p.tt() == lexer.Identifier {
-			iden = p.parseIdentifier()
-			pos = iden.Loc()
+			parent = p.parseIdentifier()
 		}
+		return p.parseAccessed(parent, pos)
+	}
+
+	return p.parseIndex()
+}
 
-		access := ast.Access{
+// Members can follow any access, call or index, as in list[0].get() or make().name
+func (p *parser) parseAccessed(parent ast.Expr, pos *location.Location) ast.Expr {
+	access := ast.Access{
+		Pos:    pos,
+		Parent: parent,
+		Access: p.expect(lexer.Access),
+		Child:  p.parseMember(),
+	}
+	for p.tt() == lexer.Access {
+		access = ast.Access{
 			Pos:    pos,
-			Parent: iden,
+			Parent: access,
 			Access: p.eat(),
 			Child:  p.parseIdentifier(),
 		}
-		for p.tt() == lexer.Access {
-			iden := p.parseIdentifier()
-			access = ast.Access{
-				Pos:    iden.Loc(),
-				Parent: access,
-				Access: p.eat(),
-				Child:  iden,
-			}
+	}
+
+	if p.tt() == lexer.OpenBracket {
+		return p.parseIndexed(access)
+	}
+
+	if p.tt() == lexer.OpenParen {
+		p.eat()
+		params := []ast.Expr{}
+		p.parseList(
+			func() {
+				params = append(params, p.parseArg())
+			},
+			[]lexer.TokenType{lexer.CloseParen},
+			[]lexer.TokenType{lexer.Delimiter},
+		)
+		return p.parseIndexed(ast.MethodCall{
+			End:    p.last().Location,
+			Method: access,
+			Params: &params,
+		})
+	} else if p.tt() == lexer.Assignment {
+		p.eat()
+		return ast.FieldAssignment{
+			Field: access,
+			Value: p.parseExpr(),
+			Op:    lexer.Token{},
+		}
+	} else if p.ptt(1) == lexer.Assignment && utils.Contains(lexer.BinaryOperator, p.tt()) {
+		op := p.eat()
+		p.expect(lexer.Assignment)
+		return ast.FieldAssignment{
+			Field: access,
+			Value: p.parseExpr(),
+			Op:    op,
 		}
-		return access
+	}
+	return access
+}
+
+func (p *parser) parseIndex() ast.Expr {
+	// New lists and maps can be used right away, as in list[int]().push(1)
+	if p.isContainer("list") || p.isContainer("map") {
+		return p.parsePrimary()
+	}
+	if p.tt() == lexer.Identifier && p.ptt(1) == lexer.OpenBracket && !p.typeName(p.at().Value) {
+		return p.parseIndexed(p.parseIdentifier())
 	}
 
 	return &ast.NoExpr{}
 }
+
+// Indexes can be chained, and the last one can be assigned to
+func (p *parser) parseIndexed(expr ast.Expr) ast.Expr {
+	for p.tt() == lexer.OpenBracket {
+		p.eat()
+		idx := p.parseExpr()
+		p.expect(lexer.CloseBracket)
+		expr = ast.Index{
+			End:   p.last().Location,
+			Array: expr,
+			Index: idx,
+		}
+	}
+
+	if p.tt() == lexer.Access {
+		return p.parseAccessed(expr, expr.Loc())
+	}
+
+	index, ok := expr.(ast.Index)
+	if !ok {
+		return expr
+	}
+	if p.tt() == lexer.Assignment {
+		p.eat()
+		return ast.IndexAssignment{
+			Index: index,
+			Value: p.parseExpr(),
+			Op:    lexer.Token{},
+		}
+	} else if p.ptt(1) == lexer.Assignment && utils.Contains(lexer.BinaryOperator, p.tt()) {
+		op := p.eat()
+		p.expect(lexer.Assignment)
+		return ast.IndexAssignment{
+			Index: index,
+			Value: p.parseExpr(),
+			Op:    op,
+		}
+	}
+	return index
+}
+
+// The parent's constructor is a member named after the new keyword, as in super.new()
+func (p *parser) parseMember() ast.Identifier {
+	if p.tt() == lexer.New {
+		tok := p.eat()
+		return ast.Identifier{
+			Pos:  tok.Location,
+			Name: tok.Value,
+		}
+	}
+	return p.parseIdentifier()
+}
@@ -20,6 +20,7 @@ type parser struct {
 	top     *ast.Scope
 	topfun  *ast.FuncScope
 	idx     int
+	blocks  int
 }
 
 func (p *parser) at() lexer.Token {
@@ -45,6 +46,10 @@ func (p *parser) next() lexer.Token {
 	return p.peek(1)
 }
 
+func (p *parser) last() lexer.Token {
+	return p.source[p.idx-1]
+}
+
 func (p *parser) eat() lexer.Token {
 	token := p.source[p.idx]
 	p.idx++
@@ -52,16 +57,16 @@ func (p *parser) eat() lexer.Token {
 }
 
 func (p *parser) expect(symbols ...lexer.TokenType) lexer.Token {
-	tok := p.eat()
+	tok := p.at()
 	if !utils.Contains(symbols, tok.Type) {
 		length := len(symbols)
 		if length == 1 {
 			if symbols[0] == lexer.CloseParen {
-				Errors.Error("Missing a parenthesis", tok.Location)
+				p.fail(UnclosedDelimiter, "Missing a parenthesis", tok.Location)
 			} else if symbols[0] == lexer.CloseBrace {
-				Errors.Error("Missing a brace", tok.Location)
+				p.fail(UnclosedDelimiter, "Missing a brace", tok.Location)
 			} else if symbols[0] == lexer.CloseBracket {
-				Errors.Error("Missing a bracket", tok.Location)
+				p.fail(UnclosedDelimiter, "Missing a bracket", tok.Location)
 			}
 		}
 
@@ -73,9 +78,9 @@ func (p *parser) expect(symbols ...lexer.TokenType) lexer.Token {
 			expected += " or " + symbols[length-1].String()
 		}
 
-		Errors.Error("Expected "+expected+", but got "+tok.Type.String()+" instead", tok.Location)
+		p.fail(UnexpectedToken, "Expected "+expected+", but got "+tok.Type.String()+" instead", tok.Location)
 	}
-	return tok
+	return p.eat()
 }
 
 func (p *parser) prefix(symbols ...lexer.TokenType) lexer.Token {
@@ -89,7 +94,7 @@ func (p *parser) is(catagory []lexer.TokenType) bool {
 	return utils.Contains(catagory, p.tt())
 }
 
-func Parse(source string, tokens *[]lexer.Token) *ast.Program {
+func Parse(source string, tokens *[]lexer.Token) (*ast.Program, []Diagnostic) {
 	prog := ast.Program{
 		References:  utils.NewSet[typing.Type](),
 		Functions:   []builtins.FuncSignature{},
@@ -99,6 +104,7 @@ func Parse(source string, tokens *[]lexer.Token) *ast.Program {
 		TypeConvs:   []builtins.TypeConvSignature{},
 		IncDecs:     []builtins.IncDecSignature{},
 		Classes:     []builtins.ClassSignature{},
+		Enums:       []builtins.EnumSignature{},
 		Strings:     []ast.String{},
 		FuncScope:   ast.NewFuncScope(nil, typing.Void),
 		Contents:    ast.Block{},
@@ -112,12 +118,13 @@ func Parse(source string, tokens *[]lexer.Token) *ast.Program {
 		scope,
 		prog.FuncScope,
 		0,
+		0,
 	}
 
 	body := []ast.Expr{}
 	p.parseList(
 		func() {
-			body = append(body, p.parseStmt())
+			body = append(body, p.statement(p.parseStmt))
 		},
 		[]lexer.TokenType{},
 		[]lexer.TokenType{lexer.NewLine, lexer.Semicolon},
@@ -129,7 +136,14 @@ func Parse(source string, tokens *[]lexer.Token) *ast.Program {
 		Scope: scope,
 	}
 
-	return &prog
+	syntax := []Diagnostic{}
+	for _, diag := range Errors.Diagnostics() {
+		if diag.Step == Parsing && diag.Severity == ErrorSeverity {
+			syntax = append(syntax, diag)
+		}
+	}
+
+	return &prog, syntax
 }
 
 func Save(prog *ast.Program, spaces int, path string) error {
@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"sulfur/src/ast"
+	. "sulfur/src/errors"
+	"sulfur/src/lexer"
+	"sulfur/src/location"
+)
+
+type bailout struct{}
+
+func (p *parser) fail(code Code, msg string, loc *location.Location) {
+	Errors.Error(code, msg, loc)
+	panic(bailout{})
+}
+
+// Parses a statement, skipping to the next synchronization point and leaving a placeholder if it has a syntax error
+func (p *parser) statement(parse func() ast.Expr) (stmt ast.Expr) {
+	start := p.at().Location
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(bailout); !ok {
+				panic(r)
+			}
+
+			p.synchronize()
+			stmt = ast.NoExpr{
+				Pos: start,
+			}
+		}
+	}()
+
+	stmt = parse()
+	if !p.terminated() {
+		p.fail(UnexpectedToken, "Expected NewLine or Semicolon, but got "+p.tt().String()+" instead", p.at().Location)
+	}
+	return stmt
+}
+
+func (p *parser) terminated() bool {
+	switch p.tt() {
+	case lexer.NewLine, lexer.Semicolon, lexer.EOF:
+		return true
+	case lexer.CloseBrace:
+		return p.blocks > 0
+	default:
+		return false
+	}
+}
+
+// Skips to the next newline or semicolon outside of any braces, or to the brace closing the current block
+func (p *parser) synchronize() {
+	depth := 0
+	for p.tt() != lexer.EOF {
+		switch p.tt() {
+		case lexer.NewLine, lexer.Semicolon:
+			if depth == 0 {
+				return
+			}
+		case lexer.OpenBrace:
+			depth++
+		case lexer.CloseBrace:
+			if depth == 0 && p.blocks > 0 {
+				return
+			}
+			depth--
+			if depth < 0 {
+				depth = 0
+			}
+		}
+		p.eat()
+	}
+}
@@ -6,6 +6,7 @@ import (
 	. "sulfur/src/errors"
 	"sulfur/src/lexer"
 	"sulfur/src/typing"
+	"sulfur/src/utils"
 )
 
 func (p *parser) parseStmt() ast.Expr {
@@ -15,8 +16,12 @@ func (p *parser) parseStmt() ast.Expr {
 		return p.parseFunction()
 	case lexer.Class:
 		return p.parseClass()
+	case lexer.Operator:
+		return p.parseOperation()
 	case lexer.Enum:
 		return p.parseEnum()
+	case lexer.Error:
+		return p.parseError()
 	case lexer.If:
 		return p.parseIfStmt()
 	case lexer.For:
@@ -33,14 +38,27 @@ func (p *parser) parseStmt() ast.Expr {
 		return p.parseBreak()
 	case lexer.Continue:
 		return p.parseContinue()
+	case lexer.Match:
+		return p.parseMatch()
+	case lexer.Fallthrough:
+		return p.parseFallthrough()
+	case lexer.Defer:
+		return p.parseDefer()
+	case lexer.Throw:
+		return p.parseThrow()
+	case lexer.Try:
+		return p.parseTry()
 	default:
+		if p.isPipe() {
+			return p.parsePipe()
+		}
 		hybrid := p.parseHybrid()
 		if !ast.Empty(hybrid) {
 			return hybrid
 		}
 	}
 
-	Errors.Error("Invalid statement", tok.Location)
+	p.fail(UnexpectedToken, "Invalid statement", tok.Location)
 	return &ast.NoExpr{
 		Pos: tok.Location,
 	}
@@ -53,12 +71,14 @@ func (p *parser) parseList(stmtgen func(), ending []lexer.TokenType, delim []lex
 			continue
 		}
 		if p.tt() == lexer.EOF {
+			p.unclosed(ending)
 			return
 		}
 
 		stmtgen()
 
 		if p.tt() == lexer.EOF {
+			p.unclosed(ending)
 			return
 		}
 		if !p.is(ending) {
@@ -73,16 +93,18 @@ func (p *parser) parseBlock() ast.Block {
 	scope := ast.NewScope()
 	scope.Parent = p.top
 	p.top = scope
+	p.blocks++
 
 	stmts := []ast.Expr{}
 	p.parseList(
 		func() {
-			stmts = append(stmts, p.parseStmt())
+			stmts = append(stmts, p.statement(p.parseStmt))
 		},
 		[]lexer.TokenType{lexer.CloseBrace},
 		[]lexer.TokenType{lexer.NewLine, lexer.Semicolon},
 	)
 
+	p.blocks--
 	p.top = scope.Parent
 	return ast.Block{
 		Pos:   tok.Location,
@@ -94,44 +116,28 @@ func (p *parser) parseBlock() ast.Block {
 func (p *parser) parseFunction() ast.Function {
 	tok := p.expect(lexer.Function)
 	name := p.parseIdentifier()
+	params := p.parseParams()
 
-	p.expect(lexer.OpenParen)
-	params := []ast.Param{}
-	p.parseList(
-		func() {
-			p := p.parseParam()
-			params = append(params, p)
-		},
-		[]lexer.TokenType{lexer.CloseParen},
-		[]lexer.TokenType{lexer.Delimiter},
-	)
+	ret := p.parseReturns()
+	rettyp := returnType(ret)
 
-	ret := ast.Identifier{}
-	if p.tt() == lexer.OpenParen {
-		p.expect(lexer.OpenParen)
-		ret = p.parseIdentifier()
-		p.expect(lexer.CloseParen)
+	throws := ast.Identifier{}
+	if p.tt() == lexer.Throws {
+		p.eat()
+		throws = p.parseIdentifier()
 	}
 
-	fnscope := ast.NewFuncScope(p.topfun, typing.Type(ret.Name))
-
-	p.topfun = fnscope
-	body := p.parseBlock()
-	body.Scope.Seperate = true
-	p.topfun = fnscope.Parent
+	fnscope, body := p.parseFuncBody(rettyp)
+	fnscope.Throws = typing.Type(throws.Name)
 
 	// TODO: Check if function already exists
-	psigs := []builtins.ParamSignature{}
-	for _, param := range params {
-		psigs = append(psigs, builtins.QuickModParam(typing.Type(param.Type.Name), param.Referenced))
-	}
-
 	sig := builtins.QuickModFunc(
 		"mod",
 		name.Name,
-		typing.Type(ret.Name),
-		psigs...,
+		rettyp,
+		p.paramSigs(params)...,
 	)
+	sig.Throws = typing.Type(throws.Name)
 	p.program.Functions = append(p.program.Functions, sig)
 
 	return ast.Function{
@@ -139,11 +145,105 @@ func (p *parser) parseFunction() ast.Function {
 		Name:      name,
 		Params:    params,
 		Return:    ret,
+		Throws:    throws,
 		FuncScope: fnscope,
 		Body:      body,
 	}
 }
 
+// Return types are listed in parentheses, like (int, string), and may be left out entirely
+func (p *parser) parseReturns() []ast.Identifier {
+	ret := []ast.Identifier{}
+	if p.tt() == lexer.OpenParen {
+		p.eat()
+		p.parseList(
+			func() {
+				ret = append(ret, p.parseType())
+			},
+			[]lexer.TokenType{lexer.CloseParen},
+			[]lexer.TokenType{lexer.Delimiter},
+		)
+	}
+	return ret
+}
+
+// Several return types are returned together as a tuple
+func returnType(ret []ast.Identifier) typing.Type {
+	switch len(ret) {
+	case 0:
+		return typing.Void
+	case 1:
+		return typing.Type(ret[0].Name)
+	}
+
+	elems := []typing.Type{}
+	for _, typ := range ret {
+		elems = append(elems, typing.Type(typ.Name))
+	}
+	return typing.Tuple(elems...)
+}
+
+// Operators with one parameter are unary, and operators with two are binary
+func (p *parser) parseOperation() ast.Operation {
+	tok := p.expect(lexer.Operator)
+	op := p.eat()
+	params := p.parseParams()
+
+	ret := p.parseReturns()
+
+	var rettyp typing.Type
+	if len(ret) == 1 {
+		rettyp = typing.Type(ret[0].Name)
+	} else {
+		Errors.Error(NoType, "An operator must return exactly one value", op.Location)
+		rettyp = typing.Invalid
+	}
+
+	fnscope, body := p.parseFuncBody(rettyp)
+	x := ast.Operation{
+		Pos:       tok.Location,
+		Op:        op,
+		Params:    params,
+		Return:    ret,
+		FuncScope: fnscope,
+		Body:      body,
+	}
+
+	switch len(params) {
+	case 1:
+		if !utils.Contains(lexer.UnaryOperator, op.Type) {
+			Errors.Error(UnexpectedToken, op.Value+" is not a unary operator", op.Location)
+			return x
+		}
+
+		unop := builtins.QuickModUnOp("mod", typing.Type(params[0].Type.Name), op.Type)
+		unop.Return = rettyp
+		p.program.UnaryOps = append(p.program.UnaryOps, unop)
+	case 2:
+		if !utils.Contains(lexer.BinaryOperator, op.Type) {
+			Errors.Error(UnexpectedToken, op.Value+" is not a binary operator", op.Location)
+			return x
+		}
+
+		left, right := typing.Type(params[0].Type.Name), typing.Type(params[1].Type.Name)
+		binop := builtins.QuickModBinOp("mod", left, right, op.Type)
+		binop.Return = rettyp
+		p.program.BinaryOps = append(p.program.BinaryOps, binop)
+
+		// The order of the operands doesn't matter, so the operation works both ways
+		if left != right {
+			swapped := builtins.QuickModBinOp("mod", right, left, op.Type)
+			swapped.Return = rettyp
+			swapped.Swapped = true
+			p.program.BinaryOps = append(p.program.BinaryOps, swapped)
+		}
+	default:
+		Errors.Error(ArgumentCount, "An operator must have either one or two parameters", x.Loc())
+	}
+
+	return x
+}
+
 func (p *parser) parseEnum() ast.Enum {
 	tok := p.expect(lexer.Enum)
 	name := p.parseIdentifier()
@@ -155,19 +255,95 @@ func (p *parser) parseEnum() ast.Enum {
 
 	p.expect(lexer.OpenBrace)
 	elems := []ast.Identifier{}
+	values := []ast.Expr{}
 	p.parseList(
 		func() {
 			elems = append(elems, p.parseIdentifier())
+			if p.tt() == lexer.Assignment {
+				p.eat()
+				values = append(values, p.parseExpr())
+			} else {
+				values = append(values, ast.NoExpr{})
+			}
 		},
 		[]lexer.TokenType{lexer.CloseBrace, lexer.EOF},
 		[]lexer.TokenType{lexer.NewLine, lexer.Semicolon},
 	)
 
+	// The type of the values is known before checking, so that enums can be used before being declared
+	var typ typing.Type = typing.Integer
+	if !ast.Empty(from) {
+		typ = typing.Type(from.Name)
+	} else {
+		for _, val := range values {
+			if !ast.Empty(val) {
+				typ = ast.LiteralType(val)
+				break
+			}
+		}
+	}
+
+	names := utils.Apply(elems, func(elem ast.Identifier) string {
+		return elem.Name
+	})
+	p.program.Enums = append(p.program.Enums, builtins.QuickModEnum("mod", name.Name, typ, names))
+
+	self := typing.Type(name.Name)
+	p.program.Comparisons = append(p.program.Comparisons,
+		builtins.QuickModComp("mod", self, lexer.EqualTo),
+		builtins.QuickModComp("mod", self, lexer.NotEqualTo),
+	)
+
+	p.program.TypeConvs = append(p.program.TypeConvs,
+		builtins.QuickModTypeConv("mod", self, typ),
+		builtins.QuickModTypeConv("mod", typ, self),
+	)
+
 	return ast.Enum{
-		Pos:   tok.Location,
-		Name:  name,
-		From:  from,
-		Elems: elems,
+		Pos:    tok.Location,
+		Name:   name,
+		From:   from,
+		Elems:  elems,
+		Values: values,
+	}
+}
+
+// Errors are numbered like enums, but their elements are only ever thrown and compared
+func (p *parser) parseError() ast.Enum {
+	tok := p.expect(lexer.Error)
+	name := p.parseIdentifier()
+
+	p.expect(lexer.OpenBrace)
+	elems := []ast.Identifier{}
+	values := []ast.Expr{}
+	p.parseList(
+		func() {
+			elems = append(elems, p.parseIdentifier())
+			values = append(values, ast.NoExpr{})
+		},
+		[]lexer.TokenType{lexer.CloseBrace, lexer.EOF},
+		[]lexer.TokenType{lexer.NewLine, lexer.Semicolon, lexer.Delimiter},
+	)
+
+	names := utils.Apply(elems, func(elem ast.Identifier) string {
+		return elem.Name
+	})
+	sig := builtins.QuickModEnum("mod", name.Name, typing.Integer, names)
+	sig.Error = true
+	p.program.Enums = append(p.program.Enums, sig)
+
+	self := typing.Type(name.Name)
+	p.program.Comparisons = append(p.program.Comparisons,
+		builtins.QuickModComp("mod", self, lexer.EqualTo),
+		builtins.QuickModComp("mod", self, lexer.NotEqualTo),
+	)
+
+	return ast.Enum{
+		Pos:    tok.Location,
+		Name:   name,
+		Elems:  elems,
+		Values: values,
+		Error:  true,
 	}
 }
 
@@ -203,8 +379,21 @@ func (p *parser) parseIfStmt() ast.IfStatement {
 	}
 }
 
-func (p *parser) parseForLoop() ast.ForLoop {
+func (p *parser) parseForLoop() ast.Expr {
 	tok := p.expect(lexer.For)
+	if p.tt() == lexer.Identifier && p.ptt(1) == lexer.In {
+		item := p.parseIdentifier()
+		p.expect(lexer.In)
+		iterable := p.parseExpr()
+		body := p.parseBlock()
+		return ast.ForEach{
+			Pos:      tok.Location,
+			Item:     item,
+			Iterable: iterable,
+			Body:     body,
+		}
+	}
+
 	init := p.parseHybrid()
 	p.expect(lexer.NewLine, lexer.Semicolon)
 	comp := p.parseComparison()
@@ -252,10 +441,64 @@ func (p *parser) parseLoop() ast.Loop {
 	}
 }
 
+func (p *parser) parseParams() []ast.Param {
+	p.expect(lexer.OpenParen)
+	params := []ast.Param{}
+	p.parseList(
+		func() {
+			params = append(params, p.parseParam())
+		},
+		[]lexer.TokenType{lexer.CloseParen},
+		[]lexer.TokenType{lexer.Delimiter},
+	)
+
+	for i, param := range params {
+		if param.Variadic && i != len(params)-1 {
+			Errors.Error(UnexpectedToken, "Only the last parameter can be variadic", param.Loc())
+		}
+	}
+	return params
+}
+
+func (p *parser) paramSigs(params []ast.Param) []builtins.ParamSignature {
+	sigs := []builtins.ParamSignature{}
+	for _, param := range params {
+		if item, ok := typing.Type(param.Type.Name).Item(); ok && param.Variadic {
+			sigs = append(sigs, builtins.QuickVariadicParam(item))
+		} else {
+			sigs = append(sigs, builtins.QuickModParam(typing.Type(param.Type.Name), param.Referenced))
+		}
+	}
+	return sigs
+}
+
+// Function bodies get their own function scope, and cannot see the variables around them
+func (p *parser) parseFuncBody(ret typing.Type) (*ast.FuncScope, ast.Block) {
+	fnscope := ast.NewFuncScope(p.topfun, ret)
+
+	p.topfun = fnscope
+	body := p.parseBlock()
+	body.Scope.Seperate = true
+	p.topfun = fnscope.Parent
+
+	return fnscope, body
+}
+
+// Variadic parameters are written ...T name, and collect the arguments left over into a T[]
 func (p *parser) parseParam() ast.Param {
-	if p.tt() == lexer.And {
+	if p.tt() == lexer.Spread {
+		spread := p.eat()
+		typ := p.parseType()
+		name := p.parseIdentifier()
+		return ast.Param{
+			Pos:      spread.Location,
+			Type:     ast.Identifier{Pos: typ.Pos, Name: string(typing.Array(typing.Type(typ.Name)))},
+			Name:     name,
+			Variadic: true,
+		}
+	} else if p.tt() == lexer.And {
 		ref := p.eat()
-		typ := p.parseIdentifier()
+		typ := p.parseType()
 		name := p.parseIdentifier()
 		return ast.Param{
 			Pos:        ref.Location,
@@ -264,7 +507,7 @@ func (p *parser) parseParam() ast.Param {
 			Referenced: true,
 		}
 	} else {
-		typ := p.parseIdentifier()
+		typ := p.parseType()
 		name := p.parseIdentifier()
 		return ast.Param{
 			Pos:        typ.Loc(),
@@ -278,6 +521,18 @@ func (p *parser) parseParam() ast.Param {
 func (p *parser) parseReturn() ast.Return {
 	tok := p.expect(lexer.Return)
 	val := p.parsePossibleExpr()
+	if p.tt() == lexer.Delimiter {
+		items := []ast.Expr{val}
+		for p.tt() == lexer.Delimiter {
+			p.eat()
+			items = append(items, p.parseExpr())
+		}
+		val = ast.Tuple{
+			Pos:   val.Loc(),
+			End:   items[len(items)-1].Loc(),
+			Items: &items,
+		}
+	}
 	return ast.Return{
 		Pos:   tok.Location,
 		Value: val,
@@ -297,3 +552,168 @@ func (p *parser) parseContinue() ast.Continue {
 		Pos: tok.Location,
 	}
 }
+
+// Arms are tried in order, and only the first one matching runs unless it falls through to the next
+func (p *parser) parseMatch() ast.Match {
+	tok := p.expect(lexer.Match)
+	val := p.parseExpr()
+	return ast.Match{
+		Pos:   tok.Location,
+		Value: val,
+		Arms:  p.parseArms(),
+	}
+}
+
+func (p *parser) parseArms() *[]ast.Arm {
+	p.expect(lexer.OpenBrace)
+	p.blocks++
+
+	arms := []ast.Arm{}
+	p.parseList(
+		func() {
+			arms = append(arms, p.parseArm())
+		},
+		[]lexer.TokenType{lexer.CloseBrace},
+		[]lexer.TokenType{lexer.NewLine, lexer.Semicolon},
+	)
+	p.blocks--
+
+	for i := range arms {
+		if i == len(arms)-1 {
+			break
+		}
+		if len(*arms[i].Patterns) == 0 {
+			Errors.Error(UnexpectedToken, "The else arm has to be the last one", arms[i].Pos)
+		}
+
+		// A fallthrough left in the last arm is reported by the checker
+		body := arms[i].Body.Body
+		if len(body) == 0 {
+			continue
+		}
+		if _, ok := body[len(body)-1].(ast.Fallthrough); ok {
+			arms[i].Body.Body = body[:len(body)-1]
+			arms[i].Fallthrough = true
+		}
+	}
+	return &arms
+}
+
+// An arm runs either a block or a single statement
+func (p *parser) parseArm() ast.Arm {
+	pos := p.at().Location
+	patterns := []ast.Expr{}
+	if p.tt() == lexer.Else {
+		p.eat()
+	} else {
+		patterns = append(patterns, p.parsePattern())
+		for p.tt() == lexer.Delimiter {
+			p.eat()
+			patterns = append(patterns, p.parsePattern())
+		}
+	}
+	p.expect(lexer.Arrow)
+
+	if p.tt() == lexer.OpenBrace {
+		return ast.Arm{
+			Pos:      pos,
+			Patterns: &patterns,
+			Body:     p.parseBlock(),
+		}
+	}
+
+	scope := ast.NewScope()
+	scope.Parent = p.top
+	p.top = scope
+	stmt := p.parseStmt()
+	p.top = scope.Parent
+
+	return ast.Arm{
+		Pos:      pos,
+		Patterns: &patterns,
+		Body: ast.Block{
+			Pos:   stmt.Loc(),
+			Body:  []ast.Expr{stmt},
+			Scope: scope,
+		},
+	}
+}
+
+func (p *parser) parsePattern() ast.Expr {
+	start := p.parseExpr()
+	if p.tt() != lexer.Range {
+		return start
+	}
+	p.eat()
+	return ast.Range{
+		Start: start,
+		End:   p.parseExpr(),
+	}
+}
+
+func (p *parser) parseFallthrough() ast.Fallthrough {
+	tok := p.expect(lexer.Fallthrough)
+	return ast.Fallthrough{
+		Pos: tok.Location,
+	}
+}
+
+func (p *parser) parseDefer() ast.Defer {
+	tok := p.expect(lexer.Defer)
+	return ast.Defer{
+		Pos:   tok.Location,
+		Value: p.parseStmt(),
+	}
+}
+
+func (p *parser) parseThrow() ast.Throw {
+	tok := p.expect(lexer.Throw)
+	return ast.Throw{
+		Pos:   tok.Location,
+		Value: p.parseExpr(),
+	}
+}
+
+func (p *parser) parseTry() ast.Try {
+	tok := p.expect(lexer.Try)
+	val := p.parsePipe()
+	if p.tt() != lexer.Catch {
+		return ast.Try{
+			Pos:   tok.Location,
+			Value: val,
+			Catch: ast.NoExpr{},
+		}
+	}
+
+	p.eat()
+	if p.tt() == lexer.OpenBrace {
+		return ast.Try{
+			Pos:   tok.Location,
+			Value: val,
+			Catch: ast.NoExpr{},
+			Arms:  p.parseArms(),
+		}
+	}
+	return ast.Try{
+		Pos:   tok.Location,
+		Value: val,
+		Catch: p.parseExpr(),
+	}
+}
+
+func (p *parser) unclosed(ending []lexer.TokenType) {
+	if len(ending) == 0 || utils.Contains(ending, lexer.EOF) {
+		return
+	}
+
+	switch ending[0] {
+	case lexer.CloseParen:
+		Errors.Error(UnclosedDelimiter, "Missing a parenthesis", p.at().Location)
+	case lexer.CloseBrace:
+		Errors.Error(UnclosedDelimiter, "Missing a brace", p.at().Location)
+	case lexer.CloseBracket:
+		Errors.Error(UnclosedDelimiter, "Missing a bracket", p.at().Location)
+	default:
+		Errors.Error(UnexpectedToken, "Expected "+ending[0].String()+", but got "+p.tt().String()+" instead", p.at().Location)
+	}
+}
@@ -3,3 +3,32 @@ package settings
 var Colored = true
 var Stacktrace = false
 var Debug = false
+var Target = ""
+var Runtime = ""
+var MaxErrors = 20
+var Diagnostics = Text
+var WarningsAsErrors = false
+
+var Warnings = map[string]bool{
+	"unused-variable":  true,
+	"unused-parameter": true,
+	"unused-function":  true,
+	"shadow":           true,
+	"unreachable":      true,
+	"conversion":       true,
+}
+
+const (
+	Text  = "text"
+	JSON  = "json"
+	SARIF = "sarif"
+)
+
+var Tools = map[string]string{
+	"llvm-as":   "llvm-as",
+	"llvm-link": "llvm-link",
+	"llvm-dis":  "llvm-dis",
+	"opt":       "opt",
+	"llc":       "llc",
+	"cc":        "cc",
+}
@@ -6,42 +6,77 @@ import (
 	"sulfur/src/errors"
 	"sulfur/src/lexer"
 	"sulfur/src/parser"
+	"sulfur/src/settings"
+	"sulfur/src/target"
 	"sulfur/src/utils"
 )
 
-func Compile(name, input, output string) {
+func Compile(name, input, output string) string {
 	code, err := lexer.GetSourceCode(input)
 	if err != nil {
 		utils.Panic(err)
 	}
 
-	errors.Errors = errors.NewErrorGenerator(code)
+	errors.Errors = errors.NewErrorGenerator(input, code)
+	defer errors.Errors.Finish()
+
+	host := target.Host()
+	tgt := host
+	if settings.Target != "" {
+		tgt, err = target.Parse(settings.Target)
+		if err != nil {
+			utils.Panic(err)
+		}
+	}
 
 	errors.Step = errors.Lexing
-	unfiltered := lexer.Lex(code)
+	unfiltered := lexer.Lex(input, code)
 	utils.AttemptSave(func() error {
 		return lexer.Save(unfiltered, "debug/unfiltered.txt")
 	})
 
+	errors.Errors.Report()
+
 	tokens := lexer.Filter(unfiltered)
 	utils.AttemptSave(func() error {
 		return lexer.Save(tokens, "debug/tokens.txt")
 	})
+	if Emit == Tokens {
+		utils.ForceSave(func() error {
+			return lexer.Save(tokens, "tmp/"+name+Tokens.Extension())
+		})
+		return tmp(name + Tokens.Extension())
+	}
 
 	errors.Step = errors.Parsing
-	ast := parser.Parse(code, tokens)
+	ast, _ := parser.Parse(code, tokens)
+	errors.Errors.Report()
 	utils.AttemptSave(func() error {
 		return parser.Save(ast, 1, "debug/ast.json")
 	})
+	if Emit == AST {
+		utils.ForceSave(func() error {
+			return parser.Save(ast, 1, "tmp/"+name+AST.Extension())
+		})
+		return tmp(name + AST.Extension())
+	}
 
 	errors.Step = errors.Inferring
 	props := checker.TypeCheck(ast)
+	errors.Errors.Report()
+	if Mode == Check {
+		return ""
+	}
 
 	errors.Step = errors.Generating
-	llcode := compiler.Generate(ast, props, input)
+	llcode := compiler.Generate(ast, props, input, tgt)
+	errors.Errors.Report()
 	utils.ForceSave(func() error {
 		return compiler.Save("; ModuleID = '"+input+"'\n"+llcode, "tmp/"+name+".ll")
 	})
+	if Emit == IR {
+		return tmp(name + ".ll")
+	}
 
-	utils.Exec("bash", utils.Absolute()+"/compile.sh", name)
+	return build(name, tgt, host)
 }
@@ -1,12 +1,18 @@
 package sulfurc
 
 import (
-	"os/exec"
+	"os"
 	"sulfur/src/utils"
 )
 
 func Clear() {
-	if _, err := exec.Command("bash", utils.Absolute()+"/clear.sh").Output(); err != nil {
-		utils.Panic(err)
+	for _, dir := range []string{"tmp", "debug"} {
+		path := utils.Relative(dir)
+		if err := os.RemoveAll(path); err != nil {
+			utils.Panic(err)
+		}
+		if err := os.MkdirAll(path, 0755); err != nil {
+			utils.Panic(err)
+		}
 	}
 }
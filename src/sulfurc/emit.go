@@ -0,0 +1,53 @@
+package sulfurc
+
+import "sulfur/src/utils"
+
+type EmitStage string
+
+const (
+	Tokens     EmitStage = "tokens"
+	AST        EmitStage = "ast"
+	IR         EmitStage = "ll"
+	Bitcode    EmitStage = "bc"
+	Assembly   EmitStage = "asm"
+	Object     EmitStage = "obj"
+	Executable EmitStage = "exe"
+)
+
+var Emit = Executable
+
+func SetEmit(emit string) {
+	if !IsEmit(emit) {
+		utils.Panic("Invalid emit stage \"" + emit + "\"")
+	}
+
+	Emit = EmitStage(emit)
+}
+
+func IsEmit(emit string) bool {
+	switch EmitStage(emit) {
+	case Tokens, AST, IR, Bitcode, Assembly, Object, Executable:
+		return true
+	default:
+		return false
+	}
+}
+
+func (e EmitStage) Extension() string {
+	switch e {
+	case Tokens:
+		return ".tokens.txt"
+	case AST:
+		return ".json"
+	case IR:
+		return ".ll"
+	case Bitcode:
+		return ".bc"
+	case Assembly:
+		return ".s"
+	case Object:
+		return ".o"
+	default:
+		return ""
+	}
+}
@@ -4,11 +4,17 @@ import (
 	"sulfur/src/utils"
 )
 
-func Execute(name string, output string) {
-	switch Mode {
-	case Run:
-		utils.Exec(utils.Absolute() + "/tmp/" + name)
-	case Build:
-		utils.Exec("cp", utils.Absolute()+"/tmp/"+name, output)
+func Execute(artifact string, output string) {
+	if Mode == Check {
+		return
 	}
+
+	if Mode == Run && Emit == Executable {
+		utils.Exec(artifact)
+		return
+	}
+
+	utils.ForceSave(func() error {
+		return utils.CopyFile(artifact, output)
+	})
 }
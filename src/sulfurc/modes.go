@@ -7,6 +7,7 @@ type CompilerMode string
 const (
 	Run   CompilerMode = "run"
 	Build CompilerMode = "build"
+	Check CompilerMode = "check"
 )
 
 var Mode CompilerMode
@@ -21,7 +22,7 @@ func SetMode(mode string) {
 
 func IsMode(mode string) bool {
 	switch CompilerMode(mode) {
-	case Run, Build:
+	case Run, Build, Check:
 		return true
 	default:
 		return false
@@ -0,0 +1,96 @@
+package sulfurc
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"sulfur/src/errors"
+	"sulfur/src/settings"
+	"sulfur/src/target"
+	"sulfur/src/utils"
+)
+
+func tmp(file string) string {
+	return utils.Relative("tmp/" + file)
+}
+
+func runtime() string {
+	if settings.Runtime != "" {
+		return settings.Runtime
+	}
+	return utils.Absolute() + "/../lib/builtin/linked.bc"
+}
+
+func stage(step errors.CompileStep, tool string, args ...string) {
+	errors.Step = step
+
+	path := settings.Tools[tool]
+	command := exec.Command(path, args...)
+
+	var stderr bytes.Buffer
+	command.Stderr = &stderr
+
+	if err := command.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) == 0 {
+			msg = err.Error()
+		}
+		utils.Panic("Error while " + string(step) + " (" + path + "):\n" + msg)
+	}
+}
+
+func disassemble(bc string) {
+	if settings.Debug {
+		stage(errors.Disassembling, "llvm-dis", bc, "-o", strings.TrimSuffix(bc, ".bc")+".ll")
+	}
+}
+
+func build(name string, tgt, host target.Target) string {
+	ll, bc := tmp(name+".ll"), tmp(name+".bc")
+	linked, optimized := tmp(name+"-linked.bc"), tmp(name+"-optimized.bc")
+
+	stage(errors.Assembling, "llvm-as", ll, "-o", bc)
+	stage(errors.Linking, "llvm-link", runtime(), bc, "-o", linked)
+	disassemble(linked)
+
+	stage(errors.Optimizing, "opt", linked, "-o", optimized)
+	disassemble(optimized)
+	if Emit == Bitcode {
+		return optimized
+	}
+
+	filetype, out := "obj", tmp(name+".o")
+	if Emit == Assembly {
+		filetype, out = "asm", tmp(name+".s")
+	}
+	stage(errors.Compiling, "llc", optimized, "-o", out, "-mtriple="+tgt.Triple(), "-filetype="+filetype, "-relocation-model=pic", "-O=3")
+	if Emit != Executable {
+		return out
+	}
+
+	exe := tmp(name)
+	args := []string{"-o", exe, out}
+	if !tgt.Equals(host) {
+		crossLinker(tgt)
+		args = append([]string{"--target=" + tgt.Triple()}, args...)
+	}
+	stage(errors.Linking, "cc", args...)
+
+	return exe
+}
+
+// Only clang-like drivers take --target, so clang is used unless another driver was given, which cannot be GCC
+func crossLinker(tgt target.Target) {
+	if settings.Tools["cc"] == "cc" {
+		settings.Tools["cc"] = "clang"
+	}
+
+	path := settings.Tools["cc"]
+	if _, err := exec.LookPath(path); err != nil {
+		utils.Panic("Linking for " + tgt.Triple() + " needs " + path + ", which could not be found, so give a cross-compiling driver with -cc=<path>")
+	}
+	version, _ := exec.Command(path, "--version").Output()
+	if strings.Contains(string(version), "Free Software Foundation") {
+		utils.Panic("Cannot link for " + tgt.Triple() + " with " + path + ", as GCC does not take --target, so use -cc=clang instead")
+	}
+}
@@ -0,0 +1,90 @@
+package target
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+)
+
+type OS string
+
+const (
+	Linux  OS = "linux"
+	Darwin OS = "darwin"
+)
+
+type Target struct {
+	Arch   string
+	Vendor string
+	OS     OS
+	Env    string
+}
+
+var layouts = map[string]string{
+	"x86_64 linux":   "e-m:e-p270:32:32-p271:32:32-p272:64:64-i64:64-f80:128-n8:16:32:64-S128",
+	"aarch64 linux":  "e-m:e-i8:8:32-i16:16:32-i64:64-i128:128-n32:64-S128",
+	"x86_64 darwin":  "e-m:o-p270:32:32-p271:32:32-p272:64:64-i64:64-f80:128-n8:16:32:64-S128",
+	"aarch64 darwin": "e-m:o-i64:64-i128:128-n32:64-S128",
+}
+
+func (t Target) Triple() string {
+	switch t.OS {
+	case Darwin:
+		arch := t.Arch
+		if arch == "aarch64" {
+			arch = "arm64"
+		}
+		return arch + "-" + t.Vendor + "-macosx"
+	default:
+		triple := t.Arch + "-" + t.Vendor + "-" + string(t.OS)
+		if t.Env != "" {
+			triple += "-" + t.Env
+		}
+		return triple
+	}
+}
+
+func (t Target) DataLayout() string {
+	return layouts[t.Arch+" "+string(t.OS)]
+}
+
+func (t Target) Equals(other Target) bool {
+	return t.Arch == other.Arch && t.OS == other.OS
+}
+
+func Host() Target {
+	tgt, err := Parse(runtime.GOARCH + "-" + runtime.GOOS)
+	if err != nil {
+		return Target{"x86_64", "pc", Linux, "gnu"}
+	}
+	return tgt
+}
+
+func Parse(triple string) (Target, error) {
+	parts := strings.Split(strings.ToLower(triple), "-")
+
+	var arch string
+	switch parts[0] {
+	case "x86_64", "amd64", "x64":
+		arch = "x86_64"
+	case "aarch64", "arm64":
+		arch = "aarch64"
+	default:
+		return Target{}, errors.New("Unsupported target architecture \"" + parts[0] + "\"")
+	}
+
+	for _, part := range parts[1:] {
+		switch {
+		case strings.HasPrefix(part, "linux"):
+			vendor := "unknown"
+			if arch == "x86_64" {
+				vendor = "pc"
+			}
+			return Target{arch, vendor, Linux, "gnu"}, nil
+		case strings.HasPrefix(part, "darwin"), strings.HasPrefix(part, "macos"):
+			return Target{arch, "apple", Darwin, ""}, nil
+		}
+	}
+
+	return Target{}, errors.New("Unsupported target \"" + triple + "\"")
+}
@@ -0,0 +1,52 @@
+package target
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		triple string
+		want   string
+	}{
+		{"x86_64-pc-linux-gnu", "x86_64-pc-linux-gnu"},
+		{"x86_64-unknown-linux-gnu", "x86_64-pc-linux-gnu"},
+		{"amd64-linux", "x86_64-pc-linux-gnu"},
+		{"aarch64-unknown-linux-gnu", "aarch64-unknown-linux-gnu"},
+		{"arm64-linux", "aarch64-unknown-linux-gnu"},
+		{"x86_64-apple-darwin", "x86_64-apple-macosx"},
+		{"arm64-apple-macosx14.0", "arm64-apple-macosx"},
+		{"AArch64-Apple-Darwin23", "arm64-apple-macosx"},
+	}
+	for _, test := range tests {
+		tgt, err := Parse(test.triple)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", test.triple, err)
+			continue
+		}
+		if got := tgt.Triple(); got != test.want {
+			t.Errorf("Parse(%q).Triple() = %q, expected %q", test.triple, got, test.want)
+		}
+		if tgt.DataLayout() == "" {
+			t.Errorf("Parse(%q) has no data layout", test.triple)
+		}
+	}
+}
+
+func TestParseUnsupported(t *testing.T) {
+	for _, triple := range []string{"", "riscv64-linux", "x86_64-pc-windows-msvc", "aarch64"} {
+		if tgt, err := Parse(triple); err == nil {
+			t.Errorf("Parse(%q) = %v, expected an error", triple, tgt)
+		}
+	}
+}
+
+func TestEquals(t *testing.T) {
+	linux, _ := Parse("x86_64-linux")
+	other, _ := Parse("amd64-unknown-linux-gnu")
+	darwin, _ := Parse("x86_64-darwin")
+	if !linux.Equals(other) {
+		t.Error("two spellings of the same target are not equal")
+	}
+	if linux.Equals(darwin) {
+		t.Error("targets with different systems are equal")
+	}
+}
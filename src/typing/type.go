@@ -1,5 +1,7 @@
 package typing
 
+import "strings"
+
 type Type string
 
 const (
@@ -11,11 +13,177 @@ const (
 	String   = "string"
 	Complex  = "complex"
 	Any      = "any"
+	Invalid  = "<invalid>"
 )
 
+var Primitives = []Type{Integer, Unsigned, Float, Boolean, String}
+
 func (t Type) String() string {
 	if t == Void {
 		return "no type"
 	}
+	if t == Invalid {
+		return "invalid type"
+	}
 	return string(t)
 }
+
+// Arrays are named after the type of their items, like int[]
+func Array(item Type) Type {
+	return item + "[]"
+}
+
+func (t Type) Item() (Type, bool) {
+	// The [] of map[int]int[] belongs to the type of the values
+	if _, _, ok := t.Entry(); ok {
+		return "", false
+	}
+	item, ok := strings.CutSuffix(string(t), "[]")
+	return Type(item), ok
+}
+
+// Lists are named after the type of their items too, like list[int]
+func List(item Type) Type {
+	return "list[" + item + "]"
+}
+
+func (t Type) ListItem() (Type, bool) {
+	if _, ok := t.Item(); ok {
+		return "", false
+	}
+	item, ok := strings.CutPrefix(string(t), "list[")
+	if !ok {
+		return "", false
+	}
+	return Type(strings.TrimSuffix(item, "]")), true
+}
+
+// Maps are named after the types of their keys and values, like map[string]int
+func Map(key, val Type) Type {
+	return "map[" + key + "]" + val
+}
+
+// Finds the types of the keys and values of a map, where the keys may have brackets of their own
+func (t Type) Entry() (Type, Type, bool) {
+	rest, ok := strings.CutPrefix(string(t), "map[")
+	if !ok {
+		return "", "", false
+	}
+
+	depth := 1
+	for i, char := range rest {
+		switch char {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		}
+		if depth == 0 {
+			return Type(rest[:i]), Type(rest[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// Tuples hold the types of several values at once, like (int, string), and only come from functions returning more than one value
+func Tuple(elems ...Type) Type {
+	names := make([]string, len(elems))
+	for i, elem := range elems {
+		names[i] = string(elem)
+	}
+	return Type("(" + strings.Join(names, ", ") + ")")
+}
+
+// Finds the types inside of a tuple, which may contain commas of their own
+func (t Type) Elems() ([]Type, bool) {
+	rest, ok := strings.CutPrefix(string(t), "(")
+	if !ok {
+		return nil, false
+	}
+	rest, ok = strings.CutSuffix(rest, ")")
+	if !ok {
+		return nil, false
+	}
+	return split(rest), true
+}
+
+// Functions are named after the types of their parameters and what they return, like func(int, string)(bool)
+func Func(params []Type, ret Type) Type {
+	names := make([]string, len(params))
+	for i, param := range params {
+		names[i] = string(param)
+	}
+
+	name := "func(" + strings.Join(names, ", ") + ")"
+	if _, ok := ret.Elems(); ok {
+		return Type(name + string(ret))
+	} else if ret != Void {
+		return Type(name + "(" + string(ret) + ")")
+	}
+	return Type(name)
+}
+
+func (t Type) Signature() ([]Type, Type, bool) {
+	rest, ok := strings.CutPrefix(string(t), "func(")
+	if _, isArray := t.Item(); !ok || isArray {
+		return nil, "", false
+	}
+
+	end := closing(rest)
+	if end == -1 {
+		return nil, "", false
+	}
+	params := []Type{}
+	if end > 0 {
+		params = split(rest[:end])
+	}
+
+	ret := rest[end+1:]
+	if ret == "" {
+		return params, Void, true
+	}
+	if !strings.HasPrefix(ret, "(") || !strings.HasSuffix(ret, ")") {
+		return nil, "", false
+	}
+	if rets := split(ret[1 : len(ret)-1]); len(rets) > 1 {
+		return params, Tuple(rets...), true
+	}
+	return params, Type(ret[1 : len(ret)-1]), true
+}
+
+// Finds the parenthesis closing the one just before the start of a name
+func closing(name string) int {
+	depth := 1
+	for i, char := range name {
+		switch char {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		}
+		if depth == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// Splits a list of types on the commas that are not inside of any of them
+func split(list string) []Type {
+	elems := []Type{}
+	depth, start := 0, 0
+	for i, char := range list {
+		switch char {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				elems = append(elems, Type(strings.TrimSpace(list[start:i])))
+				start = i + 1
+			}
+		}
+	}
+	return append(elems, Type(strings.TrimSpace(list[start:])))
+}
@@ -16,17 +16,17 @@ func Exec(cmd string, args ...string) {
 	stdout.Reset()
 	stderr.Reset()
 
-	if err := command.Run(); err != nil {
+	// Whatever the program printed before failing is still shown
+	err := command.Run()
+	if out := stdout.String(); len(out) > 0 {
+		fmt.Print(out)
+	}
+	if err != nil {
 		out := stderr.String()
 		if len(out) > 0 {
 			Panic(out)
 		} else {
 			Panic(err)
 		}
-	} else {
-		out := stdout.String()
-		if len(out) > 0 {
-			fmt.Print(out)
-		}
 	}
 }
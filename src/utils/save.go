@@ -6,7 +6,11 @@ import (
 )
 
 func SaveFile(value []byte, location string) error {
-	file, err := os.Create(Relative(location))
+	return WriteFile(value, Relative(location))
+}
+
+func WriteFile(value []byte, path string) error {
+	file, err := os.Create(path)
 	if err != nil {
 		return err
 	}
@@ -25,6 +29,23 @@ func SaveFile(value []byte, location string) error {
 	return err
 }
 
+func CopyFile(from, to string) error {
+	content, err := os.ReadFile(from)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(from)
+	if err != nil {
+		return err
+	}
+
+	if err := WriteFile(content, to); err != nil {
+		return err
+	}
+	return os.Chmod(to, info.Mode())
+}
+
 func Relative(path string) string {
 	return Absolute() + "/" + path
 }
@@ -0,0 +1,53 @@
+package utils
+
+// Counts the edits needed to turn one string into another, where swapping two neighbouring characters is a single edit
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	dist := make([][]int, len(ra)+1)
+	for i := range dist {
+		dist[i] = make([]int, len(rb)+1)
+		dist[i][0] = i
+	}
+	for j := range dist[0] {
+		dist[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			dist[i][j] = Min(Min(dist[i-1][j]+1, dist[i][j-1]+1), dist[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				dist[i][j] = Min(dist[i][j], dist[i-2][j-2]+1)
+			}
+		}
+	}
+	return dist[len(ra)][len(rb)]
+}
+
+// Finds the candidate nearest to a name, where earlier candidates win ties. Replacing every character is not a typo, so the distance must stay below the length of the name
+func Closest(name string, candidates []string) (string, bool) {
+	length := len([]rune(name))
+	best, bestDist := "", Min(Max(1, length/3)+1, length)
+	for _, candidate := range candidates {
+		if candidate == name {
+			continue
+		}
+		// Ties go to the longer candidate, since typos tend to drop letters rather than add them
+		dist := Distance(name, candidate)
+		if dist < bestDist || dist == bestDist && best != "" && len(candidate) > len(best) {
+			best, bestDist = candidate, dist
+		}
+	}
+	return best, best != ""
+}
+
+// Gives a "did you mean" hint to append to an error message, or nothing if no candidate is close enough
+func Suggest(name string, candidates []string) string {
+	if closest, ok := Closest(name, candidates); ok {
+		return ", did you mean `" + closest + "`?"
+	}
+	return ""
+}
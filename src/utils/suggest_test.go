@@ -0,0 +1,45 @@
+package utils
+
+import "testing"
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"same", "same", 0},
+		{"kitten", "sitting", 3},
+		{"lenght", "length", 1},
+		{"prnitln", "println", 1},
+		{"héllo", "hello", 1},
+	}
+	for _, test := range tests {
+		if got := Distance(test.a, test.b); got != test.want {
+			t.Errorf("Distance(%q, %q) = %d, expected %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestClosest(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []string
+		want       string
+	}{
+		{"lenght", []string{"width", "length"}, "length"},
+		{"count", []string{"count", "counts"}, "counts"},
+		{"countx", []string{"count", "counts"}, "counts"},
+		{"cx", []string{"cs", "cy"}, "cs"},
+		{"q", []string{"a", "c"}, ""},
+		{"xyz", []string{"abc"}, ""},
+		{"name", nil, ""},
+	}
+	for _, test := range tests {
+		got, ok := Closest(test.name, test.candidates)
+		if got != test.want || ok != (test.want != "") {
+			t.Errorf("Closest(%q, %q) = %q, %v, expected %q", test.name, test.candidates, got, ok, test.want)
+		}
+	}
+}